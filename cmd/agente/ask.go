@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"agente/internal/bootstrap"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// runAsk envia uma única pergunta (subcomando "ask")
+func runAsk(args []string) int {
+	fs := flag.NewFlagSet("ask", flag.ContinueOnError)
+	opts := addGenerationFlags(fs)
+	prompt := fs.String("p", "", "Pergunta (alternativa aos argumentos posicionais)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente ask [opções] [pergunta...]")
+		fmt.Fprintln(os.Stderr, "Envia uma pergunta e escreve apenas a resposta em stdout.")
+		fmt.Fprintln(os.Stderr, "O conteúdo recebido via stdin é anexado à pergunta:")
+		fmt.Fprintln(os.Stderr, "  git diff | agente ask -m <modelo> \"revise este diff\"")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	question := *prompt
+	if question == "" {
+		question = strings.Join(fs.Args(), " ")
+	}
+	return ask(*opts, question)
+}
+
+// ask envia uma única pergunta e escreve apenas a resposta em stdout.
+// Mensagens de status e erros vão para stderr.
+func ask(opts generationOptions, question string) int {
+	modelID, err := domain.MatchModel(cmp.Or(opts.model, infrastructure.DefaultModel(), domain.ModelMetaLlama33_70B))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	modelImpl, _, err := bootstrap.ResolveModel(modelID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	prompt, err := buildAskPrompt(question)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if prompt == "" {
+		fmt.Fprintln(os.Stderr, "❌ Pergunta vazia. Informe a pergunta ou envie o texto via stdin.")
+		return exitUsage
+	}
+
+	params := domain.DefaultGenerationParams()
+	if err := opts.applyParams(&params); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Parâmetro inválido: %v\n", err)
+		return exitUsage
+	}
+
+	app, err := bootstrap.New(bootstrap.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	redaction := app.NewRedaction()
+	candidates, _, err := app.Ask(context.Background(), modelImpl, modelID, redaction.Apply(prompt), nil, redaction.ApplyToParams(params))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	fmt.Println(newMarkdownRenderer(opts.raw).Render(app.RestoreAnswer(redaction, candidates[0])))
+	return exitOK
+}
+
+// buildAskPrompt combina a pergunta com o conteúdo recebido via stdin
+func buildAskPrompt(prompt string) (string, error) {
+	prompt = strings.TrimSpace(prompt)
+	if !stdinIsPiped() {
+		return prompt, nil
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler stdin: %v", err)
+	}
+
+	piped := strings.TrimSpace(string(input))
+	switch {
+	case piped == "":
+		return prompt, nil
+	case prompt == "":
+		return piped, nil
+	default:
+		return prompt + "\n\n" + piped, nil
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"agente/internal/bootstrap"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// runBatch processa um arquivo JSONL de perguntas e grava as respostas em outro JSONL.
+// A execução é retomável: IDs já presentes na saída são ignorados.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	concurrency := fs.Int("c", 4, "Número de requisições simultâneas")
+	model := fs.String("m", "", "Modelo usado nas linhas sem \"model\" (padrão: AGENTE_MODEL ou "+domain.ModelMetaLlama33_70B+")")
+	system := fs.String("system", "", "Instrução de sistema usada nas linhas sem \"system\"")
+	retryErrors := fs.Bool("retry-errors", false, "Reprocessar IDs que registraram erro na saída")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente batch [opções] entrada.jsonl saida.jsonl")
+		fmt.Fprintln(os.Stderr, "Cada linha da entrada: {\"id\": \"...\", \"prompt\": \"...\", \"model\": \"...\", \"system\": \"...\", \"params\": {...}}")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 2 || *concurrency < 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	inputPath, outputPath := fs.Arg(0), fs.Arg(1)
+	defaultModel, err := domain.MatchModel(cmp.Or(*model, infrastructure.DefaultModel(), domain.ModelMetaLlama33_70B))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	*model = defaultModel
+
+	requests, err := loadBatchRequests(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	// Resolver os modelos antes de iniciar para não falhar no meio da execução
+	for i, req := range requests {
+		if req.Model == "" {
+			continue
+		}
+		modelID, err := domain.MatchModel(req.Model)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ id %s: %v\n", req.ID, err)
+			return exitUsage
+		}
+		requests[i].Model = modelID
+	}
+
+	completed, err := domain.ReadCompletedBatchIDs(outputPath, *retryErrors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	var pending []domain.BatchRequest
+	for _, req := range requests {
+		if !completed[req.ID] {
+			pending = append(pending, req)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "📦 %d perguntas na entrada, %d já concluídas, %d a processar\n", len(requests), len(requests)-len(pending), len(pending))
+	if len(pending) == 0 {
+		return exitOK
+	}
+
+	app, err := bootstrap.New(bootstrap.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	output, err := openBatchOutput(outputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	defer output.Close()
+
+	writer := &batchWriter{encoder: json.NewEncoder(output), total: len(pending)}
+	jobs := make(chan domain.BatchRequest)
+	var wg sync.WaitGroup
+
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range jobs {
+				result := processBatchRequest(app, req, *model, *system)
+				if err := writer.write(result); err != nil {
+					fmt.Fprintf(os.Stderr, "❌ Erro ao gravar resultado %s: %v\n", req.ID, err)
+				}
+			}
+		}()
+	}
+
+	for _, req := range pending {
+		jobs <- req
+	}
+	close(jobs)
+	wg.Wait()
+
+	fmt.Fprintf(os.Stderr, "🏁 Concluído: %d sucesso(s), %d erro(s)\n", writer.done-writer.failed, writer.failed)
+	if writer.failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// processBatchRequest envia uma linha do batch usando a implementação do modelo correspondente
+func processBatchRequest(app *bootstrap.App, req domain.BatchRequest, defaultModel, defaultSystem string) domain.BatchResult {
+	modelID := req.Model
+	if modelID == "" {
+		modelID = defaultModel
+	}
+	result := domain.BatchResult{ID: req.ID, Model: modelID}
+
+	modelImpl, _, err := bootstrap.ResolveModel(modelID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	base := domain.DefaultGenerationParams()
+	base.SystemPrompt = defaultSystem
+	if req.System != "" {
+		base.SystemPrompt = req.System
+	}
+	params := req.Params.Apply(base)
+
+	redaction := app.NewRedaction()
+
+	startTime := time.Now()
+	candidates, usage, err := app.Ask(context.Background(), modelImpl, modelID, redaction.Apply(req.Prompt), nil, redaction.ApplyToParams(params))
+	result.LatencyMs = time.Since(startTime).Milliseconds()
+
+	if usage != (domain.TokenUsage{}) {
+		result.Usage = &usage
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Response = app.RestoreAnswer(redaction, candidates[0])
+	return result
+}
+
+// batchWriter serializa a gravação dos resultados e o progresso entre as goroutines
+type batchWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	total   int
+	done    int
+	failed  int
+}
+
+func (w *batchWriter) write(result domain.BatchResult) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.done++
+	status := "✅"
+	if result.Error != "" {
+		w.failed++
+		status = "❌"
+	}
+	fmt.Fprintf(os.Stderr, "%s [%d/%d] %s (%dms)\n", status, w.done, w.total, result.ID, result.LatencyMs)
+
+	return w.encoder.Encode(result)
+}
+
+// loadBatchRequests abre e lê o arquivo de entrada
+func loadBatchRequests(path string) ([]domain.BatchRequest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir entrada %s: %v", path, err)
+	}
+	defer file.Close()
+
+	return domain.ReadBatchRequests(file)
+}
+
+// openBatchOutput abre a saída para acréscimo, completando uma última linha interrompida.
+// Como as sessões e exportações, o arquivo é legível apenas pelo usuário: contém perguntas e respostas.
+func openBatchOutput(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir saída %s: %v", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("erro ao abrir saída %s: %v", path, err)
+	}
+
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil && err != io.EOF {
+			file.Close()
+			return nil, fmt.Errorf("erro ao ler saída %s: %v", path, err)
+		}
+		if last[0] != '\n' {
+			if _, err := file.Write([]byte("\n")); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("erro ao gravar saída %s: %v", path, err)
+			}
+		}
+	}
+
+	return file, nil
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"agente/internal/bootstrap"
+	"agente/internal/commands"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+	"agente/internal/render"
+)
+
+// runChat inicia a sessão interativa (subcomando "chat", padrão quando nenhum é informado)
+func runChat(args []string) int {
+	fs := flag.NewFlagSet("chat", flag.ContinueOnError)
+	opts := addGenerationFlags(fs)
+	resume := fs.String("resume", "", "Retoma uma sessão salva (ID, início do ID ou \"last\")")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente chat [opções]")
+		fmt.Fprintln(os.Stderr, "Inicia uma sessão interativa de perguntas e respostas.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	return startChat(*opts, *resume)
+}
+
+// startChat exibe o banner, escolhe o modelo (ou retoma a sessão salva) e inicia o loop de perguntas
+func startChat(opts generationOptions, resume string) int {
+	fmt.Println("🚀 Oracle AI Generative Agent")
+	fmt.Println("=============================")
+
+	// Carregar configuração OCI, cliente e mascarador
+	app, err := bootstrap.New(bootstrap.Options{ShowConfig: true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	fmt.Println()
+
+	// Criar a sessão (ou retomar a salva) com o modelo de --model, AGENTE_MODEL ou do menu
+	session, modelImpl, err := prepareSession(opts, resume)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	selectedModel, description := session.ModelID(), session.ModelName()
+
+	fmt.Printf("Usando modelo: %s (%s)\n", description, modelImpl.GetModelFamily())
+	fmt.Printf("Família: %s\n\n", modelImpl.GetModelFamily())
+	if session.QuestionCount() > 0 {
+		printResumedSession(session, modelImpl.ContextWindow())
+	}
+
+	// Iniciar sessão de múltiplas perguntas
+	state := &chatState{
+		app:           app,
+		modelImpl:     modelImpl,
+		selectedModel: selectedModel,
+		description:   description,
+		session:       session,
+		renderer:      newMarkdownRenderer(opts.raw),
+	}
+	if state.store, state.journal, err = sessionStorage(session); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	startChatSession(state)
+	return exitOK
+}
+
+// chooseModel usa o modelo informado, o padrão de AGENTE_MODEL ou, sem nenhum, o menu interativo
+func chooseModel(flagModel string) (string, error) {
+	if model := cmp.Or(flagModel, infrastructure.DefaultModel()); model != "" {
+		return model, nil
+	}
+	return domain.SelectModelInteractively()
+}
+
+// chatState reúne o que o REPL e seus comandos compartilham durante a sessão
+type chatState struct {
+	app           *bootstrap.App
+	modelImpl     domain.ModelImplementation
+	selectedModel string
+	description   string
+	session       *domain.ChatSession
+	input         lineReader
+	registry      *commands.Registry
+	renderer      *render.Renderer             // nil exibe as respostas sem formatação
+	attachments   []domain.Attachment          // Anexos pendentes, enviados com a próxima pergunta
+	codeDir       string                       // Diretório onde os blocos de código são salvos automaticamente
+	shellOutputs  []domain.ShellCommand        // Saídas de !comando pendentes, enviadas com a próxima pergunta
+	store         *infrastructure.SessionStore // nil quando o salvamento automático está desativado
+	journal       *infrastructure.Journal      // Diário das perguntas concluídas, apagado no encerramento normal
+	searchResults []domain.SearchResult        // Resultado do último /buscar, usado por /reusar
+	recalled      []domain.SearchResult        // Conversas anteriores (/reusar) pendentes, enviadas com a próxima pergunta
+}
+
+func startChatSession(state *chatState) {
+	state.registry = newChatCommands(state)
+
+	// Edição de linha, histórico e Tab quando em um terminal; leitura simples em pipes
+	state.input = newLineReader(completeChatInput(state.registry))
+	defer state.input.Close()
+
+	// Exibir instruções
+	printInstructions(state.registry)
+
+	for {
+		// Solicitar pergunta
+		fmt.Println()
+		input, err := readInput(state.input, fmt.Sprintf("📝 Pergunta %d: ", state.session.NextQuestionID()))
+		if err != nil && err != io.EOF {
+			fmt.Printf("❌ Erro ao ler entrada: %v\n", err)
+		}
+
+		// Fim da entrada (Ctrl+D, Ctrl+C ou fim do stdin): envia o que foi lido e encerra
+		ended := err != nil
+		if ended && input.Text == "" {
+			finishChatSession(state)
+			return
+		}
+		keepGoing := handleInput(state, input)
+		if err := saveSession(state); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+		if !keepGoing || ended {
+			finishChatSession(state)
+			return
+		}
+	}
+}
+
+// handleInput executa um comando ou envia a pergunta; retorna false para encerrar a sessão
+func handleInput(state *chatState, input userInput) bool {
+	if input.Text == "" {
+		fmt.Printf("⚠️  Pergunta vazia. Digite sua pergunta ou '%sajuda' para ver os comandos.\n", commands.Prefix)
+		return true
+	}
+
+	// Blocos de várias linhas são sempre perguntas
+	if input.Multiline {
+		processQuestion(state, input.Text)
+		return true
+	}
+
+	// !comando executa no shell local; só o usuário dispara, nunca o modelo
+	if strings.HasPrefix(input.Text, shellPrefix) {
+		runShell(state, input)
+		return true
+	}
+
+	// Comandos especiais começam com o prefixo; o restante é pergunta para o modelo
+	if commands.IsCommand(input.Text) {
+		err := state.registry.Execute(input.Text)
+		if err == commands.ErrExit {
+			return false
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+		return true
+	}
+
+	processQuestion(state, commands.Unescape(input.Text))
+	return true
+}
+
+// finishChatSession salva a sessão, apaga o diário e exibe as estatísticas finais
+func finishChatSession(state *chatState) {
+	fmt.Println("\n👋 Encerrando sessão...")
+	if err := closeSession(state); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	state.session.ShowStats()
+	fmt.Println("Até logo!")
+}
+
+func processQuestion(state *chatState, inputText string) {
+	// Anexos pendentes (/anexar) e citados na pergunta (@arquivo)
+	attachments, err := collectAttachments(state.attachments, inputText)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	state.attachments = nil
+	shellOutputs, recalled := state.shellOutputs, state.recalled
+	state.shellOutputs, state.recalled = nil, nil
+	prompt := domain.BuildPromptWithShellOutputs(domain.BuildPromptWithAttachments(inputText, attachments), shellOutputs)
+	prompt = domain.BuildPromptWithSearchResults(prompt, recalled)
+	var references []string
+	for _, r := range recalled {
+		references = append(references, r.Reference())
+	}
+
+	sendQuestion(state, domain.Question{
+		Text:        inputText,
+		Prompt:      sentPrompt(inputText, prompt),
+		Attachments: attachments,
+		Commands:    shellOutputs,
+		Recalled:    references,
+	}, state.session.Params())
+}
+
+// resendQuestion envia novamente uma pergunta do histórico (/regenerar, /editar) com o texto
+// informado, mantendo os anexos, comandos e buscas reutilizadas da original. Arquivos citados como
+// @arquivo no texto editado são anexados como no envio de uma pergunta nova.
+func resendQuestion(state *chatState, original domain.Question, text string, params domain.GenerationParams) {
+	attachments, err := collectAttachments(original.Attachments, text)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	prompt := domain.BuildPromptWithAttachments(text, attachments[len(original.Attachments):])
+	if extra, ok := strings.CutPrefix(original.SentText(), original.Text); ok {
+		prompt += extra
+	}
+	sendQuestion(state, domain.Question{
+		Text:        text,
+		Prompt:      sentPrompt(text, prompt),
+		Attachments: attachments,
+		Commands:    original.Commands,
+		Recalled:    original.Recalled,
+	}, params)
+}
+
+// sendQuestion envia a pergunta ao modelo, com o contexto do ramo ativo, e a registra no histórico
+func sendQuestion(state *chatState, draft domain.Question, params domain.GenerationParams) {
+	modelImpl, session := state.modelImpl, state.session
+
+	questionNumber := session.NextQuestionID()
+	fmt.Printf("🤔 Processando pergunta %d...\n", questionNumber)
+	for _, a := range draft.Attachments {
+		fmt.Printf("📎 %s\n", a.Describe())
+	}
+	for _, c := range draft.Commands {
+		fmt.Printf("🐚 %s\n", c.Describe())
+	}
+	for _, r := range draft.Recalled {
+		fmt.Printf("🔁 %s\n", r)
+	}
+
+	// O histórico guarda os parâmetros que o modelo aceita (ex.: n = 1 no Cohere)
+	draft.Model = state.selectedModel
+	draft.Params = params.ForModel(modelImpl)
+
+	out := prepareOutgoing(state, draft.SentText(), params)
+	switch {
+	case session.QuestionCount() == 0:
+		fmt.Println("🆕 Primeira pergunta da sessão")
+	case session.IsContextEnabled():
+		fmt.Printf("💭 Usando contexto de %d perguntas anteriores\n", len(out.context))
+	default:
+		fmt.Println("🧠 Contexto desativado - pergunta independente")
+	}
+	if count := out.redaction.Count(); count > 0 {
+		fmt.Printf("🛡️  %d dado(s) sensível(is) mascarado(s) antes do envio\n", count)
+	}
+
+	// Fazer a requisição
+	startTime := time.Now()
+	candidates, usage, err := out.send(context.Background())
+	processTime := time.Since(startTime)
+
+	if err != nil {
+		errorMsg := err.Error()
+		fmt.Printf("❌ %s\n", errorMsg)
+		fmt.Println("💡 Tente reformular sua pergunta ou verificar sua conexão.")
+
+		// Adicionar ao histórico como erro
+		draft.ProcessTime = processTime
+		draft.Error = errorMsg
+		if _, err := recordQuestion(state, draft); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+		return
+	}
+
+	// Com múltiplas gerações o usuário escolhe qual resposta manter no histórico
+	response := candidates[0]
+	if len(candidates) > 1 {
+		response = chooseCandidate(state, candidates)
+	}
+
+	// Adicionar ao histórico como sucesso
+	recordRedactions(session, out.redaction)
+	draft.Response = response
+	draft.ProcessTime = processTime
+	draft.Success = true
+	draft.Candidates = len(candidates)
+	draft.Usage = usage
+	question, err := recordQuestion(state, draft)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+
+	// Exibir resultado
+	printResponse(state.description, state.renderer.Render(response), questionNumber, processTime)
+	announceCodeBlocks(state, question)
+	if params.Seed != nil {
+		fmt.Printf("🎲 Seed: %d (use a mesma seed e parâmetros para reproduzir)\n", *params.Seed)
+	}
+}
+
+// outgoing é uma pergunta pronta para envio, com o texto, a instrução de sistema e o contexto
+// já mascarados. Montada no loop do chat ou da TUI; send pode rodar em segundo plano.
+type outgoing struct {
+	app       *bootstrap.App
+	modelImpl domain.ModelImplementation
+	modelID   string
+	prompt    string
+	context   []domain.Question
+	params    domain.GenerationParams
+	redaction *domain.Redaction
+}
+
+// prepareOutgoing mascara os dados sensíveis da pergunta, da instrução de sistema e do contexto do
+// ramo ativo (se ativado) antes de saírem da máquina. Os mascaramentos só entram nas estatísticas
+// da sessão com a resposta (recordRedactions).
+func prepareOutgoing(state *chatState, prompt string, params domain.GenerationParams) *outgoing {
+	session, modelImpl := state.session, state.modelImpl
+	redaction := state.app.NewRedaction()
+	out := &outgoing{
+		app:       state.app,
+		modelImpl: modelImpl,
+		modelID:   state.selectedModel,
+		prompt:    redaction.Apply(prompt),
+		params:    redaction.ApplyToParams(params.ForModel(modelImpl)),
+		redaction: redaction,
+	}
+	if session.IsContextEnabled() && session.QuestionCount() > 0 {
+		out.context = domain.SelectContext(session.Questions(), modelImpl.ContextWindow())
+		if redaction != nil {
+			out.context = redaction.ApplyToQuestions(out.context)
+		}
+	}
+	return out
+}
+
+// recordRedactions registra na sessão os dados mascarados na pergunta enviada
+func recordRedactions(session *domain.ChatSession, redaction *domain.Redaction) {
+	if redaction.Count() > 0 {
+		session.AddRedactionEvents(redaction.Events)
+	}
+}
+
+// send envia a pergunta e devolve as gerações com os valores originais restaurados, se configurado
+func (o *outgoing) send(ctx context.Context) ([]string, domain.TokenUsage, error) {
+	candidates, usage, err := o.app.Ask(ctx, o.modelImpl, o.modelID, o.prompt, o.context, o.params)
+	for i := range candidates {
+		candidates[i] = o.app.RestoreAnswer(o.redaction, candidates[i])
+	}
+	return candidates, usage, err
+}
+
+// collectAttachments junta os anexos pendentes aos citados como @arquivo e verifica o limite de tokens
+func collectAttachments(pending []domain.Attachment, inputText string) ([]domain.Attachment, error) {
+	attachments := append([]domain.Attachment(nil), pending...)
+	for _, path := range domain.FindInlineAttachments(inputText) {
+		if hasAttachment(attachments, path) {
+			continue
+		}
+		attachment, err := domain.LoadAttachment(path)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := domain.CheckAttachmentLimit(attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// hasAttachment indica se o arquivo já está entre os anexos
+func hasAttachment(attachments []domain.Attachment, path string) bool {
+	for _, a := range attachments {
+		if a.Name == path {
+			return true
+		}
+	}
+	return false
+}
+
+// sentPrompt retorna o prompt a guardar no histórico, vazio quando igual à pergunta digitada
+func sentPrompt(inputText, prompt string) string {
+	if prompt == inputText {
+		return ""
+	}
+	return prompt
+}
+
+// chooseCandidate exibe as gerações e pergunta qual deve ser mantida no histórico
+func chooseCandidate(state *chatState, candidates []string) string {
+	separator := strings.Repeat("-", 70)
+	for i, candidate := range candidates {
+		fmt.Printf("\n%s\n🔀 Geração %d de %d:\n%s\n", separator, i+1, len(candidates), separator)
+		fmt.Println(state.renderer.Render(candidate))
+	}
+	fmt.Println(separator)
+
+	for {
+		choice, _, err := state.input.ReadLine(fmt.Sprintf("Escolha a resposta a manter no histórico (1-%d) [1]: ", len(candidates)))
+		choice = strings.TrimSpace(choice)
+		if err != nil || choice == "" {
+			return candidates[0]
+		}
+
+		n, convErr := strconv.Atoi(choice)
+		if convErr == nil && n >= 1 && n <= len(candidates) {
+			return candidates[n-1]
+		}
+		fmt.Println("⚠️  Escolha inválida.")
+	}
+}
+
+func printResponse(description, response string, questionNumber int, processTime time.Duration) {
+	separator := strings.Repeat("=", 70)
+	fmt.Printf("\n%s\n", separator)
+	fmt.Printf("🤖 Resposta %d - %s:\n", questionNumber, description)
+	fmt.Printf("⚡ Processado em: %v\n", processTime.Round(time.Millisecond))
+	fmt.Printf("%s\n", separator)
+	fmt.Println(response)
+	fmt.Printf("%s\n", separator)
+}
+
+func printInstructions(registry *commands.Registry) {
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	fmt.Println("📋 INSTRUÇÕES DE USO")
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Println("• Digite suas perguntas normalmente")
+	fmt.Printf("• Comandos começam com '%s':\n", commands.Prefix)
+	fmt.Println(registry.Help())
+	fmt.Printf("• Para enviar uma pergunta que começa com '%s', use '%s%s'\n", commands.Prefix, commands.Prefix, commands.Prefix)
+	fmt.Println("• Pressione Enter após cada pergunta")
+	fmt.Printf("• Para perguntas de várias linhas, digite %s (ou %smulti), cole ou escreva o texto e termine com %s\n", multilineDelimiter, commands.Prefix, multilineDelimiter)
+	fmt.Printf("• %scomando executa no shell local e oferece incluir a saída na próxima pergunta (%s%s inclui direto)\n", shellPrefix, shellPrefix, shellPrefix)
+	fmt.Println("• 🧠 Contexto: Quando ativado, o modelo lembra das perguntas anteriores")
+	fmt.Println(strings.Repeat("=", 70))
+}
+
+func clearScreen() {
+	// Limpar tela (funciona no Windows e Unix)
+	fmt.Print("\033[2J\033[H")
+}
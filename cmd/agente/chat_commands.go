@@ -0,0 +1,996 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"agente/internal/bootstrap"
+	"agente/internal/commands"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// newChatCommands registra os comandos disponíveis no REPL
+func newChatCommands(state *chatState) *commands.Registry {
+	registry := commands.NewRegistry()
+	session := state.session
+
+	registry.Register(commands.Command{
+		Name:        "sair",
+		Aliases:     []string{"exit", "quit", "bye", "tchau", "fim"},
+		Description: "Encerrar sessão",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			return commands.ErrExit
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "ajuda",
+		Aliases:     []string{"help", "?", "comandos", "instrucoes"},
+		Description: "Mostrar estas instruções",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			printInstructions(registry)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "historico",
+		Aliases:     []string{"history", "hist"},
+		Description: "Ver histórico de perguntas",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			session.ShowHistory()
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "sessoes",
+		Aliases:     []string{"sessões", "sessions"},
+		Description: "Listar sessões salvas (a atual aparece com *)",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			store, err := openSessionStore()
+			if err != nil {
+				return err
+			}
+			summaries, err := store.List()
+			if err != nil {
+				return err
+			}
+			printSessionList(summaries, session.ID(), store.Dir())
+			if state.store == nil {
+				fmt.Println("⚠️  Salvamento automático desativado (AGENTE_SAVE_SESSIONS=false)")
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "exportar",
+		Aliases:     []string{"export"},
+		Args:        "<md|json|html|txt> [arquivo] [--plain]",
+		Description: "Exportar a sessão em arquivo (padrão: sessao-<id>.<formato>)",
+		MinArgs:     1,
+		MaxArgs:     3,
+		Complete:    commands.CompleteFrom(func() []string { return exportFormats }),
+		Handler: func(args []string) error {
+			// --plain confirma o arquivo em texto claro quando as sessões estão cifradas
+			plain := slices.Contains(args, "--plain")
+			args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--plain" })
+			if len(args) == 0 || len(args) > 2 {
+				return fmt.Errorf("uso: %sexportar <md|json|html|txt> [arquivo] [--plain]", commands.Prefix)
+			}
+			if err := requirePlainExport(plain); err != nil {
+				return err
+			}
+			data, err := exportSession(session, args[0])
+			if err != nil {
+				return err
+			}
+
+			path := exportFileName(session, args[0])
+			if len(args) == 2 {
+				path = args[1]
+			}
+			if info, err := os.Stat(path); err == nil {
+				if info.IsDir() {
+					return fmt.Errorf("%s é um diretório; informe o arquivo", path)
+				}
+				if !confirm(state, fmt.Sprintf("⚠️  %s já existe. Sobrescrever? (s/N): ", path)) {
+					fmt.Println("❎ Nada foi gravado.")
+					return nil
+				}
+			}
+
+			if err := writeExport(path, data); err != nil {
+				return err
+			}
+			fmt.Printf("💾 Sessão exportada em %s (%d pergunta(s))\n", path, session.QuestionCount())
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "importar",
+		Aliases:     []string{"import"},
+		Args:        "<arquivo.json>",
+		Description: "Importar uma conversa [{role, content}] para o histórico e o contexto",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Complete:    commands.CompleteFiles,
+		Handler: func(args []string) error {
+			return importIntoChat(state, args[0])
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "buscar",
+		Aliases:     []string{"search"},
+		Args:        "<termos...>",
+		Description: "Buscar nas perguntas e respostas das sessões salvas",
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Handler: func(args []string) error {
+			terms := domain.SearchTerms(args)
+			if len(terms) == 0 {
+				return fmt.Errorf("uso: %sbuscar <termos...>", commands.Prefix)
+			}
+			store, err := openSessionStore()
+			if err != nil {
+				return err
+			}
+			results, err := store.Search(terms, defaultSearchLimit)
+			if err != nil {
+				return err
+			}
+			state.searchResults = results
+			printSearchResults(results, terms, false)
+			if len(results) > 0 {
+				fmt.Printf("\n💡 Use %sreusar <n> para enviar uma conversa encontrada com a próxima pergunta\n", commands.Prefix)
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "reusar",
+		Aliases:     []string{"reuse"},
+		Args:        "<n>",
+		Description: "Enviar um resultado do último /buscar como contexto da próxima pergunta",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			if len(state.searchResults) == 0 {
+				return fmt.Errorf("nenhum resultado de busca; use %sbuscar <termos> primeiro", commands.Prefix)
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > len(state.searchResults) {
+				return fmt.Errorf("resultado inválido: %s (use 1 a %d)", args[0], len(state.searchResults))
+			}
+
+			result := state.searchResults[n-1]
+			for _, r := range state.recalled {
+				if r.Reference() == result.Reference() {
+					return fmt.Errorf("%s já será enviada com a próxima pergunta", r.Reference())
+				}
+			}
+			state.recalled = append(state.recalled, result)
+			fmt.Printf("🔁 %s será enviada com a próxima pergunta (~%d tokens)\n", result.Reference(),
+				domain.EstimateTokens(result.Question+result.Response))
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "stats",
+		Aliases:     []string{"estatisticas", "estatística", "statistics"},
+		Description: "Ver estatísticas da sessão",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			session.ShowStats()
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "limpar",
+		Aliases:     []string{"clear", "cls"},
+		Description: "Limpar tela",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			clearScreen()
+			fmt.Printf("🤖 Sessão ativa com %s\n", state.description)
+			fmt.Printf("📊 Perguntas feitas: %d\n", session.QuestionCount())
+			fmt.Printf("🧠 %s\n", session.GetContextStatus())
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "contexto",
+		Aliases:     []string{"context", "toggle", "alternar"},
+		Args:        "[ver]",
+		Description: "Ativar/desativar contexto, ou ver as perguntas que serão enviadas como contexto",
+		MaxArgs:     1,
+		Complete:    commands.CompleteFrom(func() []string { return []string{"ver"} }),
+		Handler: func(args []string) error {
+			if len(args) == 1 {
+				if args[0] != "ver" {
+					return fmt.Errorf("uso: %scontexto [ver]", commands.Prefix)
+				}
+				showContext(state)
+				return nil
+			}
+			session.ToggleContext()
+			fmt.Printf("🔄 %s\n", session.GetContextStatus())
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "fixar",
+		Aliases:     []string{"pin"},
+		Args:        "<n>",
+		Description: "Fixar (ou soltar) uma pergunta no contexto, mesmo fora da janela das últimas perguntas",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("pergunta inválida: %s", args[0])
+			}
+			current, _ := session.FindQuestion(id)
+			q, err := session.SetPinned(id, !current.Pinned)
+			if err != nil {
+				return err
+			}
+			if q.Pinned {
+				fmt.Printf("📌 Pergunta %d fixada: sempre fará parte do contexto\n", id)
+			} else {
+				fmt.Printf("📌 Pergunta %d solta: volta a seguir a janela de contexto\n", id)
+			}
+			if !q.Success {
+				fmt.Println("⚠️  Perguntas com erro não são enviadas como contexto")
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "excluir",
+		Aliases:     []string{"exclude"},
+		Args:        "<n>",
+		Description: "Deixar (ou voltar a colocar) uma pergunta fora do contexto, sem apagá-la do histórico",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("pergunta inválida: %s", args[0])
+			}
+			current, _ := session.FindQuestion(id)
+			q, err := session.SetExcluded(id, !current.Excluded)
+			if err != nil {
+				return err
+			}
+			if q.Excluded {
+				fmt.Printf("🚫 Pergunta %d fora do contexto (continua no histórico)\n", id)
+			} else {
+				fmt.Printf("✅ Pergunta %d de volta ao contexto\n", id)
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "esquecer",
+		Aliases:     []string{"forget"},
+		Args:        "<n>",
+		Description: "Apagar uma pergunta e sua resposta do histórico e do contexto",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("pergunta inválida: %s", args[0])
+			}
+			q, ok := session.FindQuestion(id)
+			if !ok {
+				return fmt.Errorf("pergunta não encontrada: %d", id)
+			}
+			if !confirm(state, fmt.Sprintf("Apagar a pergunta %d (%s)? (s/N): ", id, truncateLine(q.Text, 40))) {
+				fmt.Println("❌ Cancelado")
+				return nil
+			}
+			if err := forgetQuestion(state, id); err != nil {
+				return err
+			}
+			fmt.Printf("🗑️  Pergunta %d apagada da sessão\n", id)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "bom",
+		Aliases:     []string{"good"},
+		Description: "Avaliar a última resposta como boa (entra no dataset de fine-tuning)",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			q, err := session.RateLast(domain.RatingGood, "")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("👍 Resposta %d avaliada como boa\n", q.ID)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "ruim",
+		Aliases:     []string{"bad"},
+		Args:        "[comentário]",
+		Description: "Avaliar a última resposta como ruim, com um comentário opcional",
+		MaxArgs:     1,
+		FreeText:    true,
+		Handler: func(args []string) error {
+			q, err := session.RateLast(domain.RatingBad, strings.Join(args, ""))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("👎 Resposta %d avaliada como ruim\n", q.ID)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "tag",
+		Aliases:     []string{"tags"},
+		Args:        "[tag...] [-tag...]",
+		Description: "Marcar a última resposta com tags (-tag remove); sem argumentos, mostra as tags",
+		MaxArgs:     -1,
+		Handler: func(args []string) error {
+			var add, remove []string
+			for _, arg := range args {
+				if tag, ok := strings.CutPrefix(arg, "-"); ok {
+					remove = append(remove, tag)
+				} else {
+					add = append(add, arg)
+				}
+			}
+			q, err := session.TagLast(add, remove)
+			if err != nil {
+				return err
+			}
+			if len(q.Tags) == 0 {
+				fmt.Printf("🏷️  Resposta %d sem tags\n", q.ID)
+				return nil
+			}
+			fmt.Printf("🏷️  Resposta %d: %s\n", q.ID, strings.Join(q.Tags, ", "))
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "status",
+		Aliases:     []string{"estado", "contexto?", "context?"},
+		Description: "Ver status do contexto",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			fmt.Printf("📋 %s\n", session.GetContextStatus())
+			if session.IsContextEnabled() && session.QuestionCount() > 0 {
+				selected := domain.SelectContext(session.Questions(), state.modelImpl.ContextWindow())
+				fmt.Printf("💭 Perguntas no contexto: %d\n", len(selected))
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "trocar",
+		Aliases:     []string{"modelo", "change", "switch"},
+		Args:        "[modelo]",
+		Description: "Trocar de modelo mantendo o histórico (aceita parte do nome, ex.: llama 8b)",
+		MaxArgs:     -1,
+		Complete:    commands.CompleteFrom(func() []string { return domain.ModelOrder }),
+		Handler: func(args []string) error {
+			if len(args) == 0 {
+				fmt.Printf("🤖 Modelo atual: %s (%s)\n", state.description, state.selectedModel)
+				domain.ListAvailableModels()
+				fmt.Printf("💡 Use %strocar <modelo> para trocar\n", commands.Prefix)
+				return nil
+			}
+
+			// Busca parcial, como no menu inicial; só troca quando há um único candidato
+			modelID, err := domain.MatchModel(strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			modelImpl, description, err := bootstrap.ResolveModel(modelID)
+			if err != nil {
+				return err
+			}
+			state.modelImpl, state.selectedModel, state.description = modelImpl, modelID, description
+			session.SetModel(modelID, description)
+
+			fmt.Printf("🔄 Modelo alterado para %s (%s)\n", description, modelImpl.GetModelFamily())
+			if session.Params().NumGenerations > 1 && !modelImpl.SupportsMultipleGenerations() {
+				fmt.Println("⚠️  Este modelo não suporta múltiplas gerações; apenas uma resposta será gerada.")
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "parametros",
+		Aliases:     []string{"parâmetros", "params", "parameters"},
+		Description: "Ver parâmetros de geração",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			fmt.Println("🎛️  Parâmetros de geração:")
+			fmt.Println(session.Params().Describe())
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "param",
+		Args:        "<nome> <valor>",
+		Description: "Alterar parâmetro (temperatura, top_p, top_k, max_tokens, stop, frequencia, presenca, seed, n, sistema); 'padrao' restaura",
+		MinArgs:     2,
+		MaxArgs:     2,
+		FreeText:    true,
+		Handler: func(args []string) error {
+			name, value := args[0], commands.Unquote(args[1])
+			if err := session.SetParam(name, value); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Parâmetro '%s' atualizado\n", name)
+			if session.Params().NumGenerations > 1 && !state.modelImpl.SupportsMultipleGenerations() {
+				fmt.Println("⚠️  Este modelo não suporta múltiplas gerações; apenas uma resposta será gerada.")
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "regenerar",
+		Aliases:     []string{"regenerate", "regen"},
+		Args:        "[nome=valor ...]",
+		Description: "Perguntar de novo a última pergunta, opcionalmente com outros parâmetros, em um ramo novo",
+		MaxArgs:     1,
+		FreeText:    true,
+		Handler: func(args []string) error {
+			last, ok := session.LastQuestion()
+			if !ok {
+				return fmt.Errorf("nenhuma pergunta para regenerar")
+			}
+			assignments, err := commands.ParseAssignments(strings.Join(args, ""))
+			if err != nil {
+				return fmt.Errorf("uso: %sregenerar [nome=valor ...], ex.: temperatura=0.9", commands.Prefix)
+			}
+			params := session.Params()
+			for _, a := range assignments {
+				if err := params.Set(a.Name, a.Value); err != nil {
+					return err
+				}
+			}
+
+			if err := session.Rewind(last.ID); err != nil {
+				return err
+			}
+			fmt.Printf("🌿 Regenerando a pergunta %d em um ramo novo (a resposta anterior continua em %sramos)\n", last.ID, commands.Prefix)
+			resendQuestion(state, last, last.Text, params)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "editar",
+		Aliases:     []string{"edit"},
+		Args:        "<n> [novo texto]",
+		Description: "Editar uma pergunta do ramo ativo e continuar a conversa dali, em um ramo novo",
+		MinArgs:     1,
+		MaxArgs:     2,
+		FreeText:    true,
+		Handler: func(args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil || !session.IsActive(id) {
+				return fmt.Errorf("pergunta inválida: %s (use o número de uma pergunta do ramo ativo)", args[0])
+			}
+			original, _ := session.FindQuestion(id)
+
+			text := strings.Join(args[1:], "")
+			if text == "" {
+				fmt.Printf("📝 Pergunta %d: %s\n", id, original.Text)
+				line, _, err := state.input.ReadLine("✏️  Nova pergunta: ")
+				if err != nil {
+					return err
+				}
+				text = strings.TrimSpace(line)
+			}
+			if text == "" || text == original.Text {
+				fmt.Println("❌ Edição cancelada")
+				return nil
+			}
+
+			if err := session.Rewind(id); err != nil {
+				return err
+			}
+			fmt.Printf("🌿 Pergunta %d editada em um ramo novo (a conversa anterior continua em %sramos)\n", id, commands.Prefix)
+			resendQuestion(state, original, text, session.Params())
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "ramos",
+		Aliases:     []string{"branches"},
+		Description: "Listar os ramos da conversa criados por /regenerar e /editar",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			branches := session.Branches()
+			if len(branches) == 0 {
+				fmt.Println("📭 Nenhuma pergunta nesta sessão")
+				return nil
+			}
+			fmt.Println("🌿 Ramos da conversa:")
+			for _, b := range branches {
+				marker, origin := " ", "ramo ativo"
+				if b.Active {
+					marker = "*"
+				} else {
+					origin = fmt.Sprintf("diverge na pergunta %d", b.Diverge)
+				}
+				fmt.Printf("  %s %3d  %d pergunta(s), %s: %s\n", marker, b.Last.ID, b.Length, origin, truncateLine(b.Last.Text, 50))
+			}
+			if len(branches) > 1 {
+				fmt.Printf("💡 Use %sramo <n> para continuar a conversa a partir de um ramo\n", commands.Prefix)
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "ramo",
+		Aliases:     []string{"branch"},
+		Args:        "<n>",
+		Description: "Ativar o ramo que passa pela pergunta n",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("pergunta inválida: %s", args[0])
+			}
+			if err := session.SwitchBranch(id); err != nil {
+				return err
+			}
+			last, _ := session.LastQuestion()
+			fmt.Printf("🌿 Ramo ativo: %d pergunta(s), até a pergunta %d: %s\n", session.QuestionCount(), last.ID, truncateLine(last.Text, 50))
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "multi",
+		Args:        "[terminador]",
+		Description: "Digitar uma pergunta de várias linhas até o terminador (padrão: " + multilineDelimiter + ")",
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			terminator := multilineDelimiter
+			if len(args) == 1 {
+				terminator = args[0]
+			}
+
+			question, err := readMultiline(state.input, terminator)
+			if question == "" {
+				if err != nil {
+					return commands.ErrExit
+				}
+				return fmt.Errorf("pergunta vazia")
+			}
+			processQuestion(state, question)
+			if err != nil {
+				return commands.ErrExit
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "anexar",
+		Aliases:     []string{"attach"},
+		Args:        "<arquivo ...>",
+		Description: "Anexar arquivos de texto à próxima pergunta (ou use @arquivo na pergunta)",
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Complete:    commands.CompleteFiles,
+		Handler: func(args []string) error {
+			attachments := append([]domain.Attachment(nil), state.attachments...)
+			for _, path := range args {
+				if hasAttachment(attachments, path) {
+					continue
+				}
+				attachment, err := domain.LoadAttachment(path)
+				if err != nil {
+					return err
+				}
+				attachments = append(attachments, attachment)
+			}
+			if err := domain.CheckAttachmentLimit(attachments); err != nil {
+				return err
+			}
+
+			for _, a := range attachments[len(state.attachments):] {
+				fmt.Printf("📎 %s anexado\n", a.Describe())
+			}
+			state.attachments = attachments
+			fmt.Printf("💡 %d anexo(s) serão enviados com a próxima pergunta\n", len(attachments))
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "anexos",
+		Args:        "[limpar]",
+		Description: "Ver ou descartar os anexos, saídas de comandos e conversas reutilizadas pendentes",
+		MaxArgs:     1,
+		Complete:    commands.CompleteFrom(func() []string { return []string{"limpar"} }),
+		Handler: func(args []string) error {
+			if len(args) == 1 {
+				if args[0] != "limpar" {
+					return fmt.Errorf("uso: %sanexos [limpar]", commands.Prefix)
+				}
+				state.attachments = nil
+				state.shellOutputs = nil
+				state.recalled = nil
+				fmt.Println("🗑️  Anexos pendentes descartados")
+				return nil
+			}
+
+			if len(state.attachments) == 0 && len(state.shellOutputs) == 0 && len(state.recalled) == 0 {
+				fmt.Println("📎 Nenhum anexo pendente.")
+				return nil
+			}
+			fmt.Println("📎 Anexos pendentes:")
+			for _, a := range state.attachments {
+				fmt.Printf("  • %s\n", a.Describe())
+			}
+			for _, c := range state.shellOutputs {
+				fmt.Printf("  • 🐚 %s\n", c.Describe())
+			}
+			for _, r := range state.recalled {
+				fmt.Printf("  • 🔁 %s\n", r.Reference())
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "codigo",
+		Aliases:     []string{"código", "code"},
+		Args:        "[pergunta]",
+		Description: "Listar os blocos de código da última resposta (ou da pergunta informada)",
+		MaxArgs:     1,
+		Handler: func(args []string) error {
+			question, blocks, err := answerCodeBlocks(session, args)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("💻 Blocos de código da resposta %d:\n", question.ID)
+			for _, b := range blocks {
+				language := b.Language
+				if language == "" {
+					language = "sem linguagem"
+				}
+				firstLine, _, _ := strings.Cut(strings.TrimSpace(b.Content), "\n")
+				fmt.Printf("  [%d] %s, %d linha(s): %s\n", b.Index, language, b.Lines(), truncateLine(firstLine, 60))
+			}
+			fmt.Printf("💡 Use %ssalvar-codigo <n> <arquivo> para salvar um bloco\n", commands.Prefix)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "salvar-codigo",
+		Aliases:     []string{"save-code"},
+		Args:        "<n|todos> <caminho> [pergunta]",
+		Description: "Salvar um bloco de código em arquivo, ou todos em um diretório",
+		MinArgs:     2,
+		MaxArgs:     3,
+		Complete:    commands.CompleteFiles,
+		Handler: func(args []string) error {
+			question, blocks, err := answerCodeBlocks(session, args[2:])
+			if err != nil {
+				return err
+			}
+
+			if args[0] == "todos" || args[0] == "all" {
+				saved, err := saveCodeBlocks(args[1], question.ID, blocks)
+				fmt.Printf("💾 %d bloco(s) salvos em %s\n", saved, args[1])
+				return err
+			}
+
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > len(blocks) {
+				return fmt.Errorf("bloco inválido: %s (a resposta %d tem %d bloco(s))", args[0], question.ID, len(blocks))
+			}
+
+			path := args[1]
+			if info, err := os.Stat(path); err == nil {
+				if info.IsDir() {
+					return fmt.Errorf("%s é um diretório; informe o arquivo ou use '%ssalvar-codigo todos %s'", path, commands.Prefix, path)
+				}
+				if !confirm(state, fmt.Sprintf("⚠️  %s já existe. Sobrescrever? (s/N): ", path)) {
+					fmt.Println("❎ Nada foi gravado.")
+					return nil
+				}
+			}
+
+			if err := domain.WriteCodeBlock(path, blocks[n-1]); err != nil {
+				return err
+			}
+			fmt.Printf("💾 Bloco %d salvo em %s\n", n, path)
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "autosalvar-codigo",
+		Aliases:     []string{"autosave-code"},
+		Args:        "[diretório|off]",
+		Description: "Salvar automaticamente os blocos de código de cada resposta em um diretório",
+		MaxArgs:     1,
+		Complete:    commands.CompleteFiles,
+		Handler: func(args []string) error {
+			switch {
+			case len(args) == 0 && state.codeDir == "":
+				fmt.Println("💾 Salvamento automático de código desativado.")
+			case len(args) == 0:
+				fmt.Printf("💾 Blocos de código são salvos em %s\n", state.codeDir)
+			case args[0] == "off" || args[0] == "desligar":
+				state.codeDir = ""
+				fmt.Println("💾 Salvamento automático de código desativado.")
+			default:
+				state.codeDir = args[0]
+				fmt.Printf("💾 Blocos de código das próximas respostas serão salvos em %s\n", state.codeDir)
+			}
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "templates",
+		Description: "Listar templates de pergunta",
+		MaxArgs:     0,
+		Handler: func(args []string) error {
+			showTemplates()
+			return nil
+		},
+	})
+
+	registry.Register(commands.Command{
+		Name:        "usar",
+		Args:        "<nome> [var=valor ...]",
+		Description: "Perguntar usando um template",
+		MinArgs:     1,
+		MaxArgs:     2,
+		FreeText:    true,
+		Complete:    commands.CompleteFrom(templateNames),
+		Handler: func(args []string) error {
+			assignments, err := commands.ParseAssignments(strings.Join(args[1:], ""))
+			if err != nil {
+				return err
+			}
+			vars := make(map[string]string)
+			for _, a := range assignments {
+				vars[a.Name] = a.Value
+			}
+
+			question, err := renderTemplate(args[0], vars, session)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📄 Template '%s' aplicado (%d caracteres)\n", args[0], len(question))
+			processQuestion(state, question)
+			return nil
+		},
+	})
+
+	return registry
+}
+
+// showContext lista as perguntas que serão enviadas como contexto da próxima pergunta (/contexto ver)
+func showContext(state *chatState) {
+	session := state.session
+	if !session.IsContextEnabled() {
+		fmt.Printf("🧠 Contexto desativado: a próxima pergunta será enviada sem contexto (use %scontexto para ativar)\n", commands.Prefix)
+		return
+	}
+
+	window := state.modelImpl.ContextWindow()
+	selected := domain.SelectContext(session.Questions(), window)
+	if len(selected) == 0 {
+		fmt.Println("📭 Nenhuma pergunta será enviada como contexto")
+	} else {
+		fmt.Printf("💭 Contexto da próxima pergunta (%d pergunta(s), janela de %d):\n", len(selected), window)
+		for _, q := range selected {
+			marker := "  "
+			if q.Pinned {
+				marker = "📌"
+			}
+			fmt.Printf("  %s %3d  %s (~%d tokens)\n", marker, q.ID, truncateLine(q.Text, 50),
+				domain.EstimateTokens(q.SentText()+q.Response))
+		}
+	}
+
+	var excluded, failed, outside []string
+	for _, q := range session.Questions() {
+		switch {
+		case q.Excluded:
+			excluded = append(excluded, strconv.Itoa(q.ID))
+		case !q.Success:
+			failed = append(failed, strconv.Itoa(q.ID))
+		case !slices.ContainsFunc(selected, func(s domain.Question) bool { return s.ID == q.ID }):
+			outside = append(outside, strconv.Itoa(q.ID))
+		}
+	}
+	if len(excluded) > 0 {
+		fmt.Printf("🚫 Excluídas: %s\n", strings.Join(excluded, ", "))
+	}
+	if len(outside) > 0 {
+		fmt.Printf("⏭️  Fora da janela: %s (use %sfixar <n> para incluir)\n", strings.Join(outside, ", "), commands.Prefix)
+	}
+	if len(failed) > 0 {
+		fmt.Printf("❌ Com erro (nunca enviadas): %s\n", strings.Join(failed, ", "))
+	}
+}
+
+// answerCodeBlocks retorna a pergunta (a última respondida ou a de número informado) e seus blocos de código
+func answerCodeBlocks(session *domain.ChatSession, args []string) (domain.Question, []domain.CodeBlock, error) {
+	question, ok := session.LastSuccessfulQuestion()
+	if len(args) > 0 {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return domain.Question{}, nil, fmt.Errorf("pergunta inválida: %s", args[0])
+		}
+		if question, ok = session.FindQuestion(id); !ok {
+			return domain.Question{}, nil, fmt.Errorf("pergunta inválida: %s", args[0])
+		}
+		ok = question.Success
+	}
+	if !ok {
+		return domain.Question{}, nil, fmt.Errorf("nenhuma resposta disponível")
+	}
+
+	blocks := domain.ExtractCodeBlocks(question.Response)
+	if len(blocks) == 0 {
+		return question, nil, fmt.Errorf("a resposta %d não tem blocos de código", question.ID)
+	}
+	return question, blocks, nil
+}
+
+// saveCodeBlocks grava todos os blocos no diretório, sem sobrescrever arquivos existentes
+func saveCodeBlocks(dir string, questionID int, blocks []domain.CodeBlock) (int, error) {
+	saved := 0
+	for _, b := range blocks {
+		path := domain.AvailablePath(filepath.Join(dir, domain.CodeBlockFileName(questionID, b)))
+		if err := domain.WriteCodeBlock(path, b); err != nil {
+			return saved, err
+		}
+		fmt.Printf("  • %s\n", path)
+		saved++
+	}
+	return saved, nil
+}
+
+// announceCodeBlocks salva os blocos da resposta se o salvamento automático estiver ativo, ou indica como salvá-los
+func announceCodeBlocks(state *chatState, question domain.Question) {
+	blocks := domain.ExtractCodeBlocks(question.Response)
+	if len(blocks) == 0 {
+		return
+	}
+
+	if state.codeDir == "" {
+		fmt.Printf("💻 %d bloco(s) de código: use %scodigo para listar e %ssalvar-codigo para salvar\n", len(blocks), commands.Prefix, commands.Prefix)
+		return
+	}
+
+	saved, err := saveCodeBlocks(state.codeDir, question.ID, blocks)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+	}
+	fmt.Printf("💾 %d bloco(s) de código salvos em %s\n", saved, state.codeDir)
+}
+
+// confirm pergunta ao usuário e retorna true apenas para "s" ou "sim"
+func confirm(state *chatState, prompt string) bool {
+	answer, _, err := state.input.ReadLine(prompt)
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "s" || answer == "sim" || answer == "y" || answer == "yes"
+}
+
+// completeChatInput completa comandos pelo registro e caminhos de arquivos após "@"
+func completeChatInput(registry *commands.Registry) func(input string) (string, []string) {
+	return func(input string) (string, []string) {
+		word := input[strings.LastIndexAny(input, " \t")+1:]
+		if !strings.HasPrefix(word, domain.AttachmentPrefix) {
+			return registry.Complete(input)
+		}
+
+		options := commands.CompleteFiles(strings.TrimPrefix(word, domain.AttachmentPrefix))
+		for i, option := range options {
+			options[i] = domain.AttachmentPrefix + option
+		}
+		return word, options
+	}
+}
+
+// showTemplates lista os templates disponíveis e suas variáveis
+func showTemplates() {
+	dir := infrastructure.TemplatesDir()
+	library, err := domain.LoadTemplateLibrary(dir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	templates := library.List()
+	if len(templates) == 0 {
+		fmt.Printf("📄 Nenhum template encontrado em %s\n", dir)
+		fmt.Println("💡 Crie arquivos com placeholders {{variavel}}, ex.: revisao.md")
+		return
+	}
+
+	fmt.Printf("📄 Templates em %s:\n", dir)
+	for _, t := range templates {
+		variables := t.Variables()
+		if len(variables) == 0 {
+			fmt.Printf("  • %s\n", t.Name)
+			continue
+		}
+		fmt.Printf("  • %s (%s)\n", t.Name, strings.Join(variables, ", "))
+	}
+	fmt.Println("💡 Variáveis embutidas: {{data}}, {{hora}}, {{data_hora}}, {{modelo}}, {{ultima_pergunta}}, {{ultima_resposta}}, {{arquivo:caminho}}")
+}
+
+// templateNames lista os nomes dos templates disponíveis, para completar com Tab
+func templateNames() []string {
+	library, err := domain.LoadTemplateLibrary(infrastructure.TemplatesDir())
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, t := range library.List() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// renderTemplate carrega e renderiza um template com as variáveis informadas
+func renderTemplate(name string, vars map[string]string, session *domain.ChatSession) (string, error) {
+	library, err := domain.LoadTemplateLibrary(infrastructure.TemplatesDir())
+	if err != nil {
+		return "", err
+	}
+
+	template, ok := library.Get(name)
+	if !ok {
+		return "", fmt.Errorf("template não encontrado: %s (use '/templates' para listar)", name)
+	}
+
+	return domain.RenderTemplate(template, vars, session)
+}
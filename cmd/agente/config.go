@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"agente/internal/bootstrap"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// runConfig executa as ações do subcomando "config"
+func runConfig(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente config check")
+		fmt.Fprintln(os.Stderr, "Verifica variáveis OCI, chave privada, cliente e configurações opcionais.")
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 || fs.Arg(0) != "check" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	return checkConfig()
+}
+
+// checkConfig valida cada etapa da inicialização e informa o resultado
+func checkConfig() int {
+	fmt.Println("🔍 Verificando configuração...")
+
+	cfg, err := infrastructure.ReadConfig()
+	if err != nil {
+		fmt.Printf("❌ Variáveis OCI: %v\n", err)
+		return exitError
+	}
+	fmt.Println("✅ Variáveis OCI presentes")
+	cfg.PrintConfig()
+
+	provider, err := bootstrap.NewProvider(cfg)
+	if err != nil {
+		fmt.Printf("❌ Chave privada: %v\n", err)
+		return exitError
+	}
+	if _, err := provider.PrivateRSAKey(); err != nil {
+		fmt.Printf("❌ Chave privada inválida: %v\n", err)
+		return exitError
+	}
+	fmt.Println("✅ Chave privada lida com sucesso")
+
+	if _, err := bootstrap.NewClient(cfg); err != nil {
+		fmt.Printf("❌ Cliente OCI: %v\n", err)
+		return exitError
+	}
+	fmt.Println("✅ Cliente OCI criado")
+
+	if _, err := bootstrap.NewRedactor(false); err != nil {
+		fmt.Printf("❌ Mascaramento: %v\n", err)
+		return exitError
+	}
+	fmt.Println("✅ Configuração de mascaramento válida")
+
+	if model := infrastructure.DefaultModel(); model == "" {
+		fmt.Println("ℹ️  Modelo padrão não definido (AGENTE_MODEL); o chat exibirá o menu de modelos")
+	} else if id, err := domain.MatchModel(model); err != nil {
+		fmt.Printf("❌ AGENTE_MODEL: %v\n", err)
+		return exitError
+	} else {
+		fmt.Printf("✅ Modelo padrão: %s (%s)\n", id, domain.SupportedModels[id])
+	}
+
+	templatesDir := infrastructure.TemplatesDir()
+	if _, err := os.Stat(templatesDir); err != nil {
+		fmt.Printf("ℹ️  Diretório de templates não encontrado: %s\n", templatesDir)
+	} else {
+		fmt.Printf("✅ Diretório de templates: %s\n", templatesDir)
+	}
+
+	fmt.Println("🎉 Configuração OK")
+	return exitOK
+}
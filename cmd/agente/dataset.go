@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"agente/internal/bootstrap"
+	"agente/internal/domain"
+)
+
+// datasetDateLayout é o formato das datas de --since e --until
+const datasetDateLayout = "2006-01-02"
+
+// runDataset executa as ações do subcomando "dataset"
+func runDataset(args []string) int {
+	switch {
+	case len(args) > 0 && args[0] == "export":
+		return exportDataset(args[1:])
+	case len(args) > 0 && (args[0] == "-h" || args[0] == "--help"):
+		return exportDataset(args[:1])
+	default:
+		fmt.Fprintln(os.Stderr, "Uso: agente dataset export [opções] (veja agente dataset -h)")
+		return exitUsage
+	}
+}
+
+// exportDataset grava as respostas avaliadas com /bom como JSONL de prompt/completion
+// ("agente dataset export"), no formato aceito pelo fine-tuning da OCI
+func exportDataset(args []string) int {
+	fs := flag.NewFlagSet("dataset export", flag.ContinueOnError)
+	model := fs.String("model", "", "Apenas respostas deste modelo (ID ou parte do nome)")
+	fs.StringVar(model, "m", "", "Atalho para --model")
+	since := fs.String("since", "", "Apenas perguntas a partir desta data (AAAA-MM-DD)")
+	until := fs.String("until", "", "Apenas perguntas até esta data, inclusive (AAAA-MM-DD)")
+	var tags []string
+	fs.Func("tag", "Apenas respostas com a tag (/tag); repita para exigir várias", func(value string) error {
+		tags = append(tags, value)
+		return nil
+	})
+	output := fs.String("o", "", "Arquivo de saída (padrão: stdout)")
+	plain := fs.Bool("plain", false, "Exportar em texto claro mesmo com as sessões cifradas")
+	noRedact := fs.Bool("no-redact", false, "Não mascarar dados sensíveis (CPF, e-mail, chaves...) no dataset")
+	fs.Usage = func() { printDatasetUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	filter, err := datasetFilter(*model, *since, *until, tags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	// O dataset sai da máquina (upload para o fine-tuning): mascarar como nas perguntas enviadas
+	var redactor *domain.Redactor
+	if !*noRedact {
+		if redactor, err = bootstrap.NewRedactor(true); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if err := requirePlainExport(*plain); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	examples, err := store.Dataset(filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	masked := 0
+	for _, example := range examples {
+		example, count := example.Redact(redactor)
+		masked += count
+		if err := encoder.Encode(example); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data.Bytes())
+	} else if err := os.WriteFile(*output, data.Bytes(), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ erro ao gravar dataset: %v\n", err)
+		return exitError
+	}
+	if len(examples) == 0 {
+		fmt.Fprintln(os.Stderr, "⚠️  Nenhuma resposta avaliada com /bom corresponde aos filtros")
+		return exitOK
+	}
+	fmt.Fprintf(os.Stderr, "📦 %d exemplo(s) exportado(s)", len(examples))
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, " em %s", *output)
+	}
+	fmt.Fprintln(os.Stderr)
+	switch {
+	case masked > 0:
+		fmt.Fprintf(os.Stderr, "🛡️  %d dado(s) sensível(is) mascarado(s) (use --no-redact para exportar o texto original)\n", masked)
+	case redactor == nil && !*noRedact:
+		fmt.Fprintln(os.Stderr, "⚠️  Mascaramento de dados sensíveis desativado (AGENTE_REDACTION=false)")
+	}
+	return exitOK
+}
+
+// datasetFilter converte as opções de "agente dataset export" no filtro das respostas
+func datasetFilter(model, since, until string, tags []string) (domain.DatasetFilter, error) {
+	filter := domain.DatasetFilter{Tags: tags}
+	if model != "" {
+		// Uma parte do nome pode corresponder a vários modelos (ex.: "llama")
+		if filter.Models = domain.FindModels(model); len(filter.Models) == 0 {
+			return filter, fmt.Errorf("modelo desconhecido: %s (veja agente models)", model)
+		}
+	}
+	if since != "" {
+		date, err := time.ParseInLocation(datasetDateLayout, since, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("data inválida em --since: %s (use AAAA-MM-DD)", since)
+		}
+		filter.Since = date
+	}
+	if until != "" {
+		date, err := time.ParseInLocation(datasetDateLayout, until, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("data inválida em --until: %s (use AAAA-MM-DD)", until)
+		}
+		filter.Until = date.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// printDatasetUsage exibe a ajuda de "agente dataset export"
+func printDatasetUsage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Uso: agente dataset export [--model <id>] [--since AAAA-MM-DD] [--until AAAA-MM-DD]")
+	fmt.Fprintln(os.Stderr, "                            [--tag <tag>...] [-o arquivo.jsonl] [--plain] [--no-redact]")
+	fmt.Fprintln(os.Stderr, "Exporta as respostas avaliadas com /bom, de todas as sessões salvas, como JSONL")
+	fmt.Fprintln(os.Stderr, "{\"prompt\": ..., \"completion\": ...} para o fine-tuning da OCI. Dados sensíveis são")
+	fmt.Fprintln(os.Stderr, "mascarados como nas perguntas enviadas ao modelo.")
+	fmt.Fprintln(os.Stderr)
+	fs.PrintDefaults()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+
+	"agente/internal/infrastructure"
+)
+
+// sessionCipher carrega a chave dos dados gravados uma única vez por execução (a senha é pedida
+// no máximo uma vez). Retorna nil quando a criptografia não está configurada.
+var sessionCipher = sync.OnceValues(func() (*infrastructure.Cipher, error) {
+	return infrastructure.LoadCipher(readPassphrase)
+})
+
+// readPassphrase lê a senha no terminal, sem eco
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("a senha das sessões não pode ser digitada sem terminal: defina AGENTE_PASSPHRASE")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler a senha: %v", err)
+	}
+	return string(passphrase), nil
+}
+
+// openSessionStore retorna o armazenamento das sessões com a chave configurada
+func openSessionStore() (*infrastructure.SessionStore, error) {
+	cipher, err := sessionCipher()
+	if err != nil {
+		return nil, err
+	}
+	return infrastructure.NewSessionStore(infrastructure.SessionsDir(), cipher), nil
+}
+
+// loadInputHistory carrega o histórico de entradas do REPL; sem a chave, o histórico fica só em memória
+func loadInputHistory() (*infrastructure.InputHistory, error) {
+	cipher, err := sessionCipher()
+	if err != nil {
+		history, _ := infrastructure.LoadInputHistory("", infrastructure.DefaultHistoryLimit, nil)
+		return history, err
+	}
+	return infrastructure.LoadInputHistory(infrastructure.HistoryFile(), infrastructure.DefaultHistoryLimit, cipher)
+}
+
+// requirePlainExport recusa exportar sessões cifradas sem --plain: o arquivo exportado é gravado
+// em texto claro
+func requirePlainExport(plain bool) error {
+	if plain || !infrastructure.EncryptionEnabled() {
+		return nil
+	}
+	return fmt.Errorf("as sessões estão cifradas e o arquivo exportado fica em texto claro: repita com --plain")
+}
+
+// rekeySessions troca a chave das sessões, diários e histórico ("agente sessions rekey")
+func rekeySessions(args []string) int {
+	fs := flag.NewFlagSet("sessions rekey", flag.ContinueOnError)
+	decrypt := fs.Bool("decrypt", false, "Remove a criptografia, regravando os arquivos em texto claro")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente sessions rekey [--decrypt]")
+		fmt.Fprintln(os.Stderr, "A chave atual vem de AGENTE_PASSPHRASE ou AGENTE_ENCRYPTION_KEY (ou é digitada);")
+		fmt.Fprintln(os.Stderr, "a nova, de AGENTE_NEW_PASSPHRASE ou AGENTE_NEW_ENCRYPTION_KEY (ou é digitada duas vezes).")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	current, err := sessionCipher()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if *decrypt && current == nil {
+		fmt.Println("🔓 Os dados já estão em texto claro")
+		return exitOK
+	}
+
+	var key *infrastructure.EncryptionKey
+	if !*decrypt {
+		newKey, err := infrastructure.NewEncryptionKey(readPassphrase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+		key = &newKey
+	}
+
+	count, err := infrastructure.Rekey(current, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if *decrypt {
+		fmt.Printf("🔓 Criptografia removida: %d arquivo(s) regravado(s) em texto claro\n", count)
+	} else {
+		fmt.Printf("🔐 Chave trocada: %d arquivo(s) regravado(s) com a nova chave\n", count)
+		fmt.Println("💡 Nas próximas execuções, use a nova senha ou chave (AGENTE_PASSPHRASE ou AGENTE_ENCRYPTION_KEY)")
+	}
+	return exitOK
+}
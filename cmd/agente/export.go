@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"agente/internal/domain"
+	"agente/internal/render"
+)
+
+// exportFormats lista os formatos aceitos por /exportar e "agente sessions export"
+var exportFormats = []string{"md", "json", "html", "txt"}
+
+// exportSession gera a sessão no formato pedido
+func exportSession(session *domain.ChatSession, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "md", "markdown":
+		return []byte(session.ExportMarkdown()), nil
+	case "json":
+		return session.ExportJSON()
+	case "html":
+		return exportHTML(session)
+	case "txt", "text":
+		return []byte(session.ExportHistory()), nil
+	default:
+		return nil, fmt.Errorf("formato desconhecido: %s (use %s)", format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// exportFileName sugere o nome do arquivo exportado, ex.: "sessao-20250314-153012-a1b2.md"
+func exportFileName(session *domain.ChatSession, format string) string {
+	ext := strings.ToLower(format)
+	switch ext {
+	case "markdown":
+		ext = "md"
+	case "text":
+		ext = "txt"
+	}
+	return fmt.Sprintf("sessao-%s.%s", session.ID(), ext)
+}
+
+// writeExport grava o arquivo exportado, legível apenas pelo usuário: pode conter dados da conversa
+func writeExport(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("erro ao exportar sessão: %v", err)
+	}
+	return nil
+}
+
+// htmlQuestion é uma pergunta preparada para o modelo HTML
+type htmlQuestion struct {
+	domain.Question
+	Details  []string
+	Answer   template.HTML
+	Duration time.Duration
+	Other    string // Modelo da pergunta, quando difere do modelo da sessão
+}
+
+// exportHTML gera um único arquivo HTML, com o estilo embutido, para compartilhar a sessão
+func exportHTML(session *domain.ChatSession) ([]byte, error) {
+	questions := make([]htmlQuestion, 0, session.QuestionCount())
+	for _, q := range session.Questions() {
+		item := htmlQuestion{
+			Question: q,
+			Answer:   template.HTML(render.HTML(q.Response)),
+			Duration: q.ProcessTime.Round(time.Millisecond),
+		}
+		if q.Model != "" && q.Model != session.ModelID() {
+			item.Other = q.Model
+		}
+		for _, a := range q.Attachments {
+			item.Details = append(item.Details, "📎 "+a.Describe())
+		}
+		for _, c := range q.Commands {
+			item.Details = append(item.Details, "🐚 "+c.Describe())
+		}
+		for _, r := range q.Recalled {
+			item.Details = append(item.Details, "🔁 "+r)
+		}
+		if q.Source != "" {
+			item.Details = append(item.Details, "📥 Importada de "+q.Source)
+		}
+		questions = append(questions, item)
+	}
+
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, struct {
+		Session   *domain.ChatSession
+		Questions []htmlQuestion
+	}{session, questions})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao exportar sessão: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var htmlTemplate = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sessão {{.Session.ID}} - {{.Session.ModelName}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; line-height: 1.55; }
+header { border-bottom: 1px solid #d0d7de; margin-bottom: 1.5rem; }
+header p { color: #59636e; margin: .2rem 0 1rem; }
+article { margin-bottom: 2rem; }
+.question { background: #f6f8fa; border-left: 4px solid #0969da; padding: .6rem 1rem; white-space: pre-wrap; }
+.meta, .details { color: #59636e; font-size: .85rem; }
+.details { list-style: none; padding: 0; }
+.error { color: #cf222e; }
+pre { background: #f6f8fa; padding: .8rem; overflow-x: auto; border-radius: 6px; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: .3rem .6rem; }
+blockquote { border-left: 4px solid #d0d7de; margin-left: 0; padding-left: 1rem; color: #59636e; }
+</style>
+</head>
+<body>
+<header>
+<h1>Sessão de chat</h1>
+<p>{{.Session.ModelName}} ({{.Session.ModelID}}) · iniciada em {{.Session.StartTime.Format "02/01/2006 15:04"}} · {{len .Questions}} pergunta(s)</p>
+</header>
+{{range .Questions}}<article>
+<h2>Pergunta {{.ID}}</h2>
+<p class="meta">{{.Timestamp.Format "02/01/2006 15:04:05"}}{{with .Other}} · {{.}}{{end}}</p>
+<div class="question">{{.Text}}</div>
+{{with .Details}}<ul class="details">{{range .}}<li>{{.}}</li>{{end}}</ul>
+{{end}}{{if .Success}}<div class="answer">
+{{.Answer}}
+</div>
+{{if not .Source}}<p class="meta">Processado em {{.Duration}}{{with .Params.Seed}} · seed {{.}}{{end}}{{with .Usage.TotalTokens}} · {{.}} tokens{{end}}</p>
+{{end}}{{else}}<p class="error">Erro: {{.Error}}</p>
+{{end}}</article>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+
+	"agente/internal/bootstrap"
+	"agente/internal/commands"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// runImport converte uma transcrição no formato de mensagens da OpenAI em uma sessão salva
+// (subcomando "import"), que pode ser continuada com "agente chat --resume"
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	model := fs.String("model", "", "Modelo usado ao continuar a conversa (padrão: AGENTE_MODEL)")
+	fs.StringVar(model, "m", "", "Atalho para --model")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente import [--model <id>] <arquivo.json>")
+		fmt.Fprintln(os.Stderr, "Importa uma conversa no formato [{\"role\": ..., \"content\": ...}] (ou {\"messages\": [...]})")
+		fmt.Fprintln(os.Stderr, "como uma sessão salva.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	modelID, err := domain.MatchModel(cmp.Or(*model, infrastructure.DefaultModel(), domain.ModelMetaLlama33_70B))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	_, description, err := bootstrap.ResolveModel(modelID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	transcript, err := domain.LoadTranscript(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	session := domain.NewChatSession(modelID, description)
+	session.ImportTranscript(transcript)
+	store, err := openSessionStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if err := store.Save(session); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	printImportSummary(transcript, true)
+	fmt.Printf("💾 Sessão %s criada em %s\n", session.ID(), store.Dir())
+	if transcript.Pending != "" {
+		fmt.Printf("💬 A última mensagem ficou sem resposta: %s\n", truncateLine(transcript.Pending, 70))
+	}
+	fmt.Printf("💡 Continue a conversa com: agente chat --resume %s\n", session.ID())
+	return exitOK
+}
+
+// importIntoChat acrescenta a transcrição à sessão atual (/importar) e oferece enviar
+// a última mensagem do usuário, se ela ficou sem resposta
+func importIntoChat(state *chatState, path string) error {
+	transcript, err := domain.LoadTranscript(path)
+	if err != nil {
+		return err
+	}
+
+	usedSystem := state.session.ImportTranscript(transcript)
+	printImportSummary(transcript, usedSystem)
+	if transcript.System != "" && !usedSystem {
+		fmt.Printf("⚠️  A sessão já tem instrução de sistema; a da transcrição foi ignorada (veja %sparametros)\n", commands.Prefix)
+	}
+	if len(transcript.Questions) > 0 {
+		fmt.Println("💭 As perguntas importadas fazem parte do contexto das próximas perguntas")
+	}
+
+	if transcript.Pending != "" {
+		fmt.Printf("💬 A última mensagem ficou sem resposta: %s\n", truncateLine(transcript.Pending, 70))
+		if confirm(state, "Enviar essa mensagem agora? (s/N): ") {
+			processQuestion(state, transcript.Pending)
+		}
+	}
+	return nil
+}
+
+// printImportSummary resume o que foi importado
+func printImportSummary(transcript domain.Transcript, usedSystem bool) {
+	fmt.Printf("📥 %d pergunta(s) importada(s)\n", len(transcript.Questions))
+	if transcript.System != "" && usedSystem {
+		fmt.Printf("🧭 Instrução de sistema: %s\n", truncateLine(transcript.System, 70))
+	}
+	if transcript.Skipped > 0 {
+		fmt.Printf("⏭️  %d mensagem(ns) ignorada(s) (ferramentas, sem texto ou sem pergunta)\n", transcript.Skipped)
+	}
+}
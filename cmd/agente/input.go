@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"agente/internal/infrastructure"
+)
+
+// multilineDelimiter abre e fecha um bloco de várias linhas no REPL
+const multilineDelimiter = `"""`
+
+// continuationPrompt é exibido nas linhas seguintes de um bloco
+const continuationPrompt = "... "
+
+// lineReader lê linhas do usuário: com edição no terminal ou leitura simples em pipes.
+// pasted indica que a linha veio de um bloco colado, já reunido em um único texto.
+type lineReader interface {
+	ReadLine(prompt string) (line string, pasted bool, err error)
+	Close()
+}
+
+// userInput é uma entrada lida do REPL
+type userInput struct {
+	Text      string
+	Multiline bool // Bloco de várias linhas ou texto colado: sempre enviado como pergunta
+	Pasted    bool // Texto colado, não digitado
+}
+
+// newLineReader usa o editor de linha quando stdin e stdout são terminais.
+// complete recebe o texto antes do cursor e retorna a palavra em edição e as opções.
+func newLineReader(complete func(input string) (string, []string)) lineReader {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) || os.Getenv("TERM") == "dumb" {
+		return &plainReader{reader: bufio.NewReader(os.Stdin)}
+	}
+
+	history, err := loadInputHistory()
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	// O histórico é gravado por ReadLine, que ignora linhas coladas
+	terminal.History = readOnlyHistory{history}
+	terminal.AutoCompleteCallback = completionCallback(complete)
+	terminal.SetBracketedPasteMode(true)
+
+	return &terminalReader{fd: fd, terminal: terminal, history: history}
+}
+
+// plainReader lê linhas sem edição (stdin redirecionado ou terminal sem suporte)
+type plainReader struct {
+	reader *bufio.Reader
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, bool, error) {
+	fmt.Print(prompt)
+	line, err := r.reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false, err
+	}
+	return strings.TrimRight(line, "\r\n"), false, err
+}
+
+func (r *plainReader) Close() {}
+
+// terminalReader oferece edição de linha, histórico persistente e completar com Tab
+type terminalReader struct {
+	fd       int
+	terminal *term.Terminal
+	history  *infrastructure.InputHistory
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, bool, error) {
+	line, err := r.readRaw(prompt)
+	if err != term.ErrPasteIndicator {
+		if err == nil {
+			r.history.Add(line)
+		}
+		return line, false, err
+	}
+
+	// Texto colado chega linha a linha; junta tudo até uma linha que não foi colada.
+	// Se a colagem termina com quebra de linha, Enter em uma linha vazia envia o bloco.
+	lines := []string{line}
+	for {
+		line, err = r.readRaw(continuationPrompt)
+		if err == term.ErrPasteIndicator {
+			lines = append(lines, line)
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n"), true, err
+	}
+}
+
+// readRaw lê uma linha com o terminal em modo raw, restaurando-o em seguida
+func (r *terminalReader) readRaw(prompt string) (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state)
+
+	if width, height, err := term.GetSize(r.fd); err == nil && width > 0 {
+		r.terminal.SetSize(width, height)
+	}
+	r.terminal.SetPrompt(prompt)
+	return r.terminal.ReadLine()
+}
+
+func (r *terminalReader) Close() {
+	r.terminal.SetBracketedPasteMode(false)
+}
+
+// readOnlyHistory expõe o histórico para navegação sem deixar o terminal gravar nele
+type readOnlyHistory struct {
+	*infrastructure.InputHistory
+}
+
+func (readOnlyHistory) Add(string) {}
+
+// completionCallback completa com Tab a palavra antes do cursor até o maior prefixo comum
+func completionCallback(complete func(input string) (string, []string)) func(string, int, rune) (string, int, bool) {
+	return func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		word, options := complete(line[:pos])
+		if len(options) == 0 {
+			return line, pos, true
+		}
+
+		completion := options[0]
+		for _, option := range options[1:] {
+			completion = commonPrefix(completion, option)
+		}
+		if len(options) == 1 && !strings.HasSuffix(completion, string(os.PathSeparator)) {
+			completion += " "
+		}
+		if len(completion) < len(word) {
+			return line, pos, true
+		}
+
+		newLine := line[:pos-len(word)] + completion + line[pos:]
+		return newLine, pos - len(word) + len(completion), true
+	}
+}
+
+// commonPrefix retorna o maior prefixo comum entre duas strings
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
+
+// readInput lê a próxima entrada do usuário. Uma linha com apenas """ inicia um bloco
+// de várias linhas, encerrado por outra linha com """; texto colado é mantido junto.
+func readInput(reader lineReader, prompt string) (userInput, error) {
+	line, pasted, err := reader.ReadLine(prompt)
+	if pasted {
+		text := strings.TrimSpace(line)
+		return userInput{Text: text, Multiline: strings.Contains(text, "\n"), Pasted: true}, err
+	}
+
+	if err == nil && strings.TrimSpace(line) == multilineDelimiter {
+		text, err := readMultiline(reader, multilineDelimiter)
+		return userInput{Text: text, Multiline: true}, err
+	}
+
+	return userInput{Text: strings.TrimSpace(line)}, err
+}
+
+// readMultiline lê linhas até encontrar o terminador e retorna o bloco sem ele
+func readMultiline(reader lineReader, terminator string) (string, error) {
+	fmt.Printf("📋 Modo de várias linhas: termine com uma linha contendo apenas %s\n", terminator)
+
+	var lines []string
+	for {
+		line, _, err := reader.ReadLine(continuationPrompt)
+		if strings.TrimSpace(line) == terminator {
+			return strings.TrimSpace(strings.Join(lines, "\n")), nil
+		}
+		if line != "" || err == nil {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			return strings.TrimSpace(strings.Join(lines, "\n")), err
+		}
+	}
+}
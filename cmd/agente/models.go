@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"agente/internal/domain"
+)
+
+// modelInfo é a representação JSON de um modelo em "agente models --json"
+type modelInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Family      string `json:"family"`
+}
+
+// runModels lista os modelos suportados (subcomando "models")
+func runModels(args []string) int {
+	fs := flag.NewFlagSet("models", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Saída em JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente models [--json]")
+		fmt.Fprintln(os.Stderr, "Lista os modelos suportados e suas famílias.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if !*asJSON {
+		domain.ListAvailableModels()
+		return exitOK
+	}
+
+	models := make([]modelInfo, 0, len(domain.ModelOrder))
+	for _, modelID := range domain.ModelOrder {
+		description, family, _ := domain.GetModelInfo(modelID)
+		models = append(models, modelInfo{ID: modelID, Description: description, Family: family})
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(models); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
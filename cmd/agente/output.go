@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+
+	"agente/internal/render"
+)
+
+// newMarkdownRenderer retorna o renderizador de respostas, ou nil para exibir o texto sem formatação:
+// com --raw, com NO_COLOR definido, em terminais "dumb" ou quando a saída não é um terminal
+func newMarkdownRenderer(raw bool) *render.Renderer {
+	fd := int(os.Stdout.Fd())
+	if raw || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !term.IsTerminal(fd) {
+		return nil
+	}
+
+	return render.New(func() int {
+		width, _, err := term.GetSize(fd)
+		if err != nil {
+			return 0
+		}
+		return width
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"agente/internal/domain"
+)
+
+// Quantidade padrão de resultados exibidos por /buscar e "agente search"
+const defaultSearchLimit = 10
+
+// runSearch busca perguntas e respostas nas sessões salvas (subcomando "search")
+func runSearch(args []string) int {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("n", defaultSearchLimit, "Quantidade máxima de resultados (0 = todos)")
+	full := fs.Bool("full", false, "Exibe a resposta completa em vez de um trecho")
+	asJSON := fs.Bool("json", false, "Saída em JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente search [opções] <termos...>")
+		fmt.Fprintln(os.Stderr, "Busca nas perguntas e respostas das sessões salvas. Todos os termos precisam aparecer;")
+		fmt.Fprintln(os.Stderr, "termos entre aspas são buscados como frase.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	terms := domain.SearchTerms(fs.Args())
+	if len(terms) == 0 || *limit < 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	results, err := store.Search(terms, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	if *asJSON {
+		if results == nil {
+			results = []domain.SearchResult{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	printSearchResults(results, terms, *full)
+	return exitOK
+}
+
+// printSearchResults exibe os resultados numerados, com a origem e um trecho da resposta
+func printSearchResults(results []domain.SearchResult, terms []string, full bool) {
+	if len(results) == 0 {
+		fmt.Println("🔎 Nenhuma resposta encontrada.")
+		return
+	}
+
+	fmt.Printf("🔎 %d resultado(s):\n", len(results))
+	for i, r := range results {
+		fmt.Printf("\n%d. 📂 %s · %s · %s\n", i+1, r.Reference(), r.Model, r.Timestamp.Format("02/01/2006 15:04"))
+		fmt.Printf("   ❓ %s\n", truncateLine(r.Question, 100))
+		if full {
+			fmt.Printf("   💬 %s\n", r.Response)
+		} else {
+			fmt.Printf("   💬 %s\n", r.Snippet(terms, 160))
+		}
+	}
+}
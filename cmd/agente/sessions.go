@@ -0,0 +1,316 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"agente/internal/bootstrap"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// runSessions executa as ações do subcomando "sessions"
+func runSessions(args []string) int {
+	fs := flag.NewFlagSet("sessions", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente sessions <list|show|export|rekey> [opções]")
+		fmt.Fprintln(os.Stderr, "  list              Lista as sessões salvas")
+		fmt.Fprintln(os.Stderr, "  show <id>         Mostra o histórico de uma sessão")
+		fmt.Fprintln(os.Stderr, "  export [--format md|json|html|txt] [-o arquivo] [--plain] <id>")
+		fmt.Fprintln(os.Stderr, "                    Exporta uma sessão (padrão: texto em stdout); com")
+		fmt.Fprintln(os.Stderr, "                    criptografia ativa, --plain confirma o arquivo em texto claro")
+		fmt.Fprintln(os.Stderr, "  rekey [--decrypt] Troca a chave dos dados cifrados (ou remove a criptografia)")
+		fmt.Fprintf(os.Stderr, "O ID pode ser abreviado pelo início ou ser %q (a mais recente).\n", infrastructure.LastSession)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	if fs.Arg(0) == "rekey" {
+		return rekeySessions(fs.Args()[1:])
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	switch action := fs.Arg(0); action {
+	case "list":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			return exitUsage
+		}
+		summaries, err := store.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+		printSessionList(summaries, "", store.Dir())
+		return exitOK
+
+	case "show":
+		if fs.NArg() != 2 {
+			fs.Usage()
+			return exitUsage
+		}
+		session, err := store.Load(fs.Arg(1))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+		session.ShowHistory()
+		return exitOK
+
+	case "export":
+		return exportStoredSession(store, fs.Args()[1:])
+
+	default:
+		fs.Usage()
+		return exitUsage
+	}
+}
+
+// exportStoredSession exporta uma sessão salva ("agente sessions export")
+func exportStoredSession(store *infrastructure.SessionStore, args []string) int {
+	fs := flag.NewFlagSet("sessions export", flag.ContinueOnError)
+	format := fs.String("format", "txt", "Formato: "+strings.Join(exportFormats, ", "))
+	output := fs.String("o", "", "Arquivo de saída (padrão: stdout)")
+	plain := fs.Bool("plain", false, "Exportar em texto claro mesmo com as sessões cifradas")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Uso: agente sessions export [--format md|json|html|txt] [-o arquivo] [--plain] <id>")
+		return exitUsage
+	}
+	if err := requirePlainExport(*plain); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	session, err := store.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	data, err := exportSession(session, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return exitOK
+	}
+	if err := writeExport(*output, data); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(os.Stderr, "💾 Sessão exportada em %s\n", *output)
+	return exitOK
+}
+
+// newSessionStore retorna o armazenamento das sessões, ou nil se AGENTE_SAVE_SESSIONS=false
+func newSessionStore() (*infrastructure.SessionStore, error) {
+	if !infrastructure.SessionsEnabled() {
+		return nil, nil
+	}
+	return openSessionStore()
+}
+
+// sessionStorage retorna o armazenamento e o diário da sessão (ambos nil sem salvamento automático)
+func sessionStorage(session *domain.ChatSession) (*infrastructure.SessionStore, *infrastructure.Journal, error) {
+	store, err := newSessionStore()
+	if store == nil {
+		return nil, nil, err
+	}
+	return store, store.Journal(session), nil
+}
+
+// offerRecovery procura sessões que terminaram sem ser encerradas (queda, terminal fechado) e,
+// da mais recente para a mais antiga, pergunta se cada uma deve ser recuperada. Nada é alterado
+// sem a confirmação: o diário só é juntado ao arquivo salvo ao retomar a sessão (SessionStore.Resume).
+// Retorna o ID da sessão a retomar, ou "" para começar uma nova.
+func offerRecovery() string {
+	store, err := newSessionStore()
+	if err != nil || store == nil {
+		return ""
+	}
+	unfinished, err := store.Unfinished()
+	if err != nil || len(unfinished) == 0 {
+		return ""
+	}
+
+	for _, u := range unfinished {
+		fmt.Printf("🩹 A sessão %s não foi encerrada corretamente (%d pergunta(s) no diário, última às %s)\n",
+			u.ID, u.Questions, u.UpdatedAt.Format("02/01 15:04"))
+		if domain.ConfirmInteractively("Recuperar e continuar esta sessão? (s/N): ") {
+			return u.ID
+		}
+		fmt.Printf("💡 O diário continua no disco; recupere depois com: agente chat --resume %s\n\n", u.ID)
+	}
+	return ""
+}
+
+// recordQuestion registra a pergunta na sessão e no diário, antes de qualquer outra gravação
+func recordQuestion(state *chatState, question domain.Question) (domain.Question, error) {
+	question = state.session.RecordQuestion(question)
+	if state.journal == nil {
+		return question, nil
+	}
+	if err := state.journal.Append(question); err != nil {
+		return question, fmt.Errorf("não foi possível gravar o diário da sessão: %v", err)
+	}
+	return question, nil
+}
+
+// forgetQuestion apaga a pergunta da sessão e registra o apagamento no diário, para que uma
+// recuperação não a traga de volta
+func forgetQuestion(state *chatState, id int) error {
+	if _, err := state.session.Forget(id); err != nil {
+		return err
+	}
+	if state.journal == nil {
+		return nil
+	}
+	if err := state.journal.AppendForget(id); err != nil {
+		return fmt.Errorf("não foi possível gravar o diário da sessão: %v", err)
+	}
+	return nil
+}
+
+// closeSession salva a sessão no encerramento normal e apaga o diário, que já não é necessário
+func closeSession(state *chatState) error {
+	if err := saveSession(state); err != nil {
+		return err
+	}
+	if state.journal == nil {
+		return nil
+	}
+	return state.journal.Remove()
+}
+
+// prepareSession cria a sessão ou retoma a salva, resolve o modelo e aplica os parâmetros das opções.
+// Ao retomar, o modelo da sessão é mantido, a menos que --model seja informado.
+func prepareSession(opts generationOptions, resume string) (*domain.ChatSession, domain.ModelImplementation, error) {
+	var session *domain.ChatSession
+	var selectedModel string
+	// Uma chave errada interrompe aqui, antes que algo seja lido ou gravado
+	if _, err := sessionCipher(); err != nil {
+		return nil, nil, err
+	}
+	if resume == "" {
+		resume = offerRecovery()
+	}
+	if resume != "" {
+		store, err := openSessionStore()
+		if err != nil {
+			return nil, nil, err
+		}
+		loaded, recovered, err := store.Resume(resume)
+		if err != nil {
+			return nil, nil, err
+		}
+		if recovered > 0 {
+			fmt.Printf("🩹 %d pergunta(s) recuperada(s) do diário da sessão %s\n", recovered, loaded.ID())
+		}
+		session = loaded
+		selectedModel = cmp.Or(opts.model, session.ModelID())
+	} else {
+		model, err := chooseModel(opts.model)
+		if err != nil {
+			return nil, nil, err
+		}
+		selectedModel = model
+	}
+
+	// --model, AGENTE_MODEL e o modelo da sessão aceitam a mesma busca parcial do menu
+	selectedModel, err := domain.MatchModel(selectedModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	modelImpl, description, err := bootstrap.ResolveModel(selectedModel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session == nil {
+		session = domain.NewChatSession(selectedModel, description)
+	}
+	session.SetModel(selectedModel, description)
+	if err := session.UpdateParams(opts.applyParams); err != nil {
+		return nil, nil, fmt.Errorf("parâmetro inválido: %v", err)
+	}
+	return session, modelImpl, nil
+}
+
+// saveSession grava a sessão quando o salvamento automático está ativo e já há perguntas
+func saveSession(state *chatState) error {
+	if state.store == nil || state.session.QuestionCount() == 0 {
+		return nil
+	}
+	if err := state.store.Save(state.session); err != nil {
+		return fmt.Errorf("não foi possível salvar a sessão: %v", err)
+	}
+	return nil
+}
+
+// printResumedSession resume a sessão retomada e a última troca de mensagens
+func printResumedSession(session *domain.ChatSession, window int) {
+	fmt.Printf("📂 Sessão %s retomada: %d pergunta(s), iniciada em %s\n",
+		session.ID(), session.QuestionCount(), session.StartTime().Format("02/01/2006 15:04"))
+
+	if last, ok := session.LastSuccessfulQuestion(); ok {
+		fmt.Printf("❓ Última pergunta: %s\n", truncateLine(last.Text, 70))
+		fmt.Printf("🤖 Última resposta: %s\n", truncateLine(last.Response, 70))
+	}
+	fmt.Println(session.GetContextStatus())
+	if session.IsContextEnabled() {
+		if count := len(domain.SelectContext(session.Questions(), window)); count > 0 {
+			fmt.Printf("💭 %d pergunta(s) anterior(es) serão usadas como contexto\n", count)
+		}
+	}
+}
+
+// printSessionList exibe as sessões salvas, marcando a sessão atual
+func printSessionList(summaries []domain.SessionSummary, currentID, dir string) {
+	if len(summaries) == 0 {
+		fmt.Printf("📂 Nenhuma sessão salva em %s\n", dir)
+		return
+	}
+
+	fmt.Printf("📂 Sessões salvas (%s):\n", dir)
+	for _, s := range summaries {
+		marker := " "
+		if s.ID == currentID {
+			marker = "*"
+		}
+		fmt.Printf("%s %s  %s  %2d pergunta(s)  %s\n", marker, s.ID, s.UpdatedAt.Format("02/01/2006 15:04"),
+			s.Questions, truncateLine(s.FirstQuestion, 50))
+	}
+	fmt.Printf("💡 Retome com: agente chat --resume <id|%s>\n", infrastructure.LastSession)
+}
+
+// truncateLine reduz o texto a uma linha de até limit caracteres
+func truncateLine(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"agente/internal/infrastructure"
+)
+
+// shellPrefix inicia um comando local: "!cmd" pergunta se inclui a saída, "!!cmd" inclui direto
+const shellPrefix = "!"
+
+// runShell executa o comando digitado pelo usuário e guarda a saída para a próxima pergunta.
+// Só é chamado a partir da entrada do usuário; respostas do modelo nunca executam comandos.
+func runShell(state *chatState, input userInput) {
+	command := strings.TrimPrefix(input.Text, shellPrefix)
+	automatic := strings.HasPrefix(command, shellPrefix)
+	if automatic {
+		command = strings.TrimPrefix(command, shellPrefix)
+	}
+	command = strings.TrimSpace(command)
+
+	if command == "" {
+		fmt.Println("⚠️  Uso: !comando (pergunta se inclui a saída) ou !!comando (inclui automaticamente)")
+		return
+	}
+
+	// Texto colado pode conter comandos que o usuário não leu
+	if input.Pasted && !confirm(state, fmt.Sprintf("⚠️  Executar o comando colado `%s`? (s/N): ", command)) {
+		fmt.Println("❌ Comando não executado")
+		return
+	}
+
+	timeout, err := infrastructure.ShellTimeout()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	fmt.Printf("🐚 Executando: %s\n", command)
+	result := infrastructure.RunShellCommand(command, timeout, os.Stdout)
+	if result.Error != "" {
+		fmt.Printf("❌ %s\n", result.Error)
+	} else {
+		fmt.Printf("🐚 Código de saída %d em %v\n", result.ExitCode, result.Duration.Round(time.Millisecond))
+	}
+	if result.Truncated {
+		fmt.Printf("✂️  Saída cortada: mantidos o início e o fim (%d KB)\n", len(result.Output)/1024)
+	}
+
+	if !automatic && !confirm(state, "📎 Incluir a saída na próxima pergunta? (s/N): ") {
+		fmt.Println("🗑️  Saída descartada")
+		return
+	}
+	state.shellOutputs = append(state.shellOutputs, result)
+	fmt.Println("📎 Saída será enviada com a próxima pergunta")
+}
@@ -0,0 +1,619 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+
+	"agente/internal/bootstrap"
+	"agente/internal/commands"
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+	"agente/internal/render"
+)
+
+// Dimensões fixas do layout da TUI
+const (
+	tuiSidebarWidth = 34 // Painel lateral, incluindo a borda
+	tuiMinWidth     = 80 // Abaixo disso o painel lateral é ocultado
+	tuiInputHeight  = 3  // Linhas visíveis da caixa de entrada
+)
+
+var (
+	tuiBorder   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+	tuiTitle    = lipgloss.NewStyle().Bold(true)
+	tuiQuestion = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	tuiMuted    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	tuiError    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// runTUI inicia a interface de tela cheia (subcomando "tui")
+func runTUI(args []string) int {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	opts := addGenerationFlags(fs)
+	resume := fs.String("resume", "", "Retoma uma sessão salva (ID, início do ID ou \"last\")")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Uso: agente tui [opções]")
+		fmt.Fprintln(os.Stderr, "Inicia a sessão interativa em tela cheia, com painel de conversa, entrada e status.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprintln(os.Stderr, "❌ O modo tui requer um terminal; use 'agente chat' ou 'agente ask' com entrada redirecionada")
+		return exitUsage
+	}
+
+	app, err := bootstrap.New(bootstrap.Options{Quiet: true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+
+	session, modelImpl, err := prepareSession(*opts, *resume)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	state := &chatState{
+		app:           app,
+		modelImpl:     modelImpl,
+		selectedModel: session.ModelID(),
+		description:   session.ModelName(),
+		session:       session,
+	}
+	if state.store, state.journal, err = sessionStorage(session); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	model := newTUIModel(state, opts.raw)
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro na interface: %v\n", err)
+		return exitError
+	}
+
+	finishChatSession(state)
+	return exitOK
+}
+
+// tuiRequest é a pergunta em andamento; só uma é enviada por vez
+type tuiRequest struct {
+	text        string
+	prompt      string
+	attachments []domain.Attachment
+	params      domain.GenerationParams
+	redaction   *domain.Redaction // Registrada na sessão só se a resposta chegar
+	started     time.Time
+	cancel      context.CancelFunc
+}
+
+// tuiChoice é uma resposta com várias gerações aguardando a escolha do usuário
+type tuiChoice struct {
+	question   domain.Question
+	candidates []string
+	started    time.Time
+}
+
+// tuiAnswerMsg traz a resposta do modelo para o loop da interface
+type tuiAnswerMsg struct {
+	candidates  []string
+	usage       domain.TokenUsage
+	err         error
+	processTime time.Duration
+}
+
+// tuiTickMsg atualiza a duração da sessão e o tempo de espera a cada segundo
+type tuiTickMsg time.Time
+
+// tuiModel é o estado da interface. A sessão só é alterada no loop da interface (Update);
+// a requisição em segundo plano recebe cópias do que precisa.
+type tuiModel struct {
+	state        *chatState
+	renderer     *render.Renderer
+	conversation viewport.Model
+	input        textarea.Model
+	history      *infrastructure.InputHistory
+	historyPos   int    // -1 enquanto o usuário edita; senão, índice em history.At
+	draft        string // Texto em edição antes de navegar pelo histórico
+	request      *tuiRequest
+	choice       *tuiChoice // Gerações aguardando a escolha; a entrada fica bloqueada até lá
+	notice       string
+	width        int
+	height       int
+}
+
+func newTUIModel(state *chatState, raw bool) *tuiModel {
+	input := textarea.New()
+	input.Placeholder = "Digite sua pergunta..."
+	input.Prompt = ""
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.SetHeight(tuiInputHeight)
+	input.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	input.Focus()
+
+	history, err := loadInputHistory()
+	notice := ""
+	if err != nil {
+		notice = fmt.Sprintf("⚠️  %v", err)
+	}
+
+	m := &tuiModel{
+		state:        state,
+		conversation: viewport.New(0, 0),
+		input:        input,
+		history:      history,
+		historyPos:   -1,
+		notice:       notice,
+	}
+	// A rolagem é feita pelos atalhos da interface; as teclas pertencem à caixa de entrada
+	m.conversation.KeyMap = viewport.KeyMap{}
+	if !raw && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" {
+		m.renderer = render.New(func() int { return m.conversation.Width })
+	}
+	return m
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return tea.Batch(textarea.Blink, tuiTick())
+}
+
+func tuiTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tuiTickMsg(t) })
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		m.refreshConversation(true)
+		return m, nil
+
+	case tuiTickMsg:
+		if m.request != nil {
+			m.refreshConversation(false)
+		}
+		return m, tuiTick()
+
+	case tuiAnswerMsg:
+		m.finishRequest(msg)
+		return m, nil
+
+	case tea.KeyMsg:
+		if cmd, handled := m.handleKey(msg); handled {
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// handleKey trata os atalhos da interface; as demais teclas vão para a caixa de entrada
+func (m *tuiModel) handleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.choice != nil {
+		return m.handleChoiceKey(msg)
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "ctrl+d":
+		if m.request != nil {
+			m.request.cancel()
+		}
+		return tea.Quit, true
+
+	case "esc":
+		if m.request != nil {
+			m.request.cancel()
+		}
+		return nil, true
+
+	case "enter":
+		return m.submit(), true
+
+	case "ctrl+t":
+		m.state.session.ToggleContext()
+		m.notice = m.state.session.GetContextStatus()
+		m.save()
+		return nil, true
+
+	case "pgup":
+		m.conversation.HalfPageUp()
+		return nil, true
+	case "pgdown":
+		m.conversation.HalfPageDown()
+		return nil, true
+	case "ctrl+home":
+		m.conversation.GotoTop()
+		return nil, true
+	case "ctrl+end":
+		m.conversation.GotoBottom()
+		return nil, true
+
+	case "ctrl+p":
+		m.browseHistory(1)
+		return nil, true
+	case "ctrl+n":
+		m.browseHistory(-1)
+		return nil, true
+	case "up":
+		// Em textos de várias linhas as setas movem o cursor
+		if m.input.LineCount() == 1 {
+			m.browseHistory(1)
+			return nil, true
+		}
+	case "down":
+		if m.input.LineCount() == 1 {
+			m.browseHistory(-1)
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// handleChoiceKey trata as teclas enquanto as gerações aguardam a escolha: o número escolhe,
+// Enter e Esc mantêm a primeira e a rolagem continua disponível
+func (m *tuiModel) handleChoiceKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch key := msg.String(); key {
+	case "ctrl+c", "ctrl+d":
+		m.chooseCandidate(0)
+		return tea.Quit, true
+	case "enter", "esc":
+		m.chooseCandidate(0)
+	case "pgup":
+		m.conversation.HalfPageUp()
+	case "pgdown":
+		m.conversation.HalfPageDown()
+	case "ctrl+home":
+		m.conversation.GotoTop()
+	case "ctrl+end":
+		m.conversation.GotoBottom()
+	default:
+		if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(m.choice.candidates) {
+			m.chooseCandidate(n - 1)
+		}
+	}
+	return nil, true
+}
+
+// browseHistory percorre as entradas anteriores (delta 1) ou volta para as mais recentes (delta -1)
+func (m *tuiModel) browseHistory(delta int) {
+	if m.history == nil {
+		return
+	}
+	pos := m.historyPos + delta
+	if pos < -1 || pos >= m.history.Len() {
+		return
+	}
+	if m.historyPos == -1 {
+		m.draft = m.input.Value()
+	}
+
+	m.historyPos = pos
+	if pos == -1 {
+		m.input.SetValue(m.draft)
+	} else {
+		m.input.SetValue(m.history.At(pos))
+	}
+	m.input.CursorEnd()
+}
+
+// submit envia o texto da caixa de entrada ao modelo em segundo plano
+func (m *tuiModel) submit() tea.Cmd {
+	text := strings.TrimSpace(m.input.Value())
+	if text == "" {
+		return nil
+	}
+	if m.request != nil {
+		m.notice = "⏳ Aguarde a resposta ou pressione Esc para cancelar"
+		return nil
+	}
+	if commands.IsCommand(text) {
+		m.notice = fmt.Sprintf("⚠️  Comandos %s não estão disponíveis na TUI; use os atalhos do painel ou %s%s para enviar texto começando com %s",
+			commands.Prefix, commands.Prefix, commands.Prefix, commands.Prefix)
+		return nil
+	}
+	text = commands.Unescape(text)
+
+	// Arquivos citados como @arquivo, como no chat
+	attachments, err := collectAttachments(nil, text)
+	if err != nil {
+		m.notice = fmt.Sprintf("❌ %v", err)
+		return nil
+	}
+	prompt := domain.BuildPromptWithAttachments(text, attachments)
+
+	params := m.state.session.Params()
+	out := prepareOutgoing(m.state, prompt, params)
+
+	m.notice = ""
+	if count := out.redaction.Count(); count > 0 {
+		m.notice = fmt.Sprintf("🛡️  %d dado(s) sensível(is) mascarado(s) antes do envio", count)
+	}
+
+	m.history.Add(text)
+	m.historyPos, m.draft = -1, ""
+	m.input.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.request = &tuiRequest{
+		text:        text,
+		prompt:      prompt,
+		attachments: attachments,
+		params:      params.ForModel(m.state.modelImpl),
+		redaction:   out.redaction,
+		started:     time.Now(),
+		cancel:      cancel,
+	}
+	m.refreshConversation(true)
+
+	return func() tea.Msg {
+		start := time.Now()
+		candidates, usage, err := out.send(ctx)
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return tuiAnswerMsg{candidates: candidates, usage: usage, err: err, processTime: time.Since(start)}
+	}
+}
+
+// finishRequest registra a resposta (ou o erro) na sessão
+func (m *tuiModel) finishRequest(msg tuiAnswerMsg) {
+	request := m.request
+	m.request = nil
+	if request == nil {
+		return
+	}
+	request.cancel()
+
+	// Pergunta cancelada não entra no histórico; o texto volta para a caixa de entrada
+	if errors.Is(msg.err, context.Canceled) {
+		m.input.SetValue(request.text)
+		m.input.CursorEnd()
+		m.notice = "⛔ Pergunta cancelada"
+		m.refreshConversation(true)
+		return
+	}
+
+	question := domain.Question{
+		Model:       m.state.selectedModel,
+		Text:        request.text,
+		Prompt:      sentPrompt(request.text, request.prompt),
+		Attachments: request.attachments,
+		ProcessTime: msg.processTime,
+		Params:      request.params,
+		Usage:       msg.usage,
+	}
+	if msg.err != nil {
+		question.Error = msg.err.Error()
+	} else {
+		recordRedactions(m.state.session, request.redaction)
+		question.Success = true
+		question.Response = msg.candidates[0]
+		question.Candidates = len(msg.candidates)
+	}
+
+	// Com múltiplas gerações o usuário escolhe qual resposta manter no histórico
+	if len(msg.candidates) > 1 {
+		m.choice = &tuiChoice{question: question, candidates: msg.candidates, started: request.started}
+		m.notice = fmt.Sprintf("🔀 Escolha a resposta a manter no histórico: 1-%d (Enter mantém a 1)", len(msg.candidates))
+		m.refreshConversation(true)
+		return
+	}
+	m.record(question)
+}
+
+// chooseCandidate mantém a geração escolhida (índice a partir de 0) e registra a pergunta
+func (m *tuiModel) chooseCandidate(index int) {
+	choice := m.choice
+	m.choice = nil
+	choice.question.Response = choice.candidates[index]
+	m.notice = ""
+	m.record(choice.question)
+}
+
+// record registra a pergunta respondida na sessão e grava
+func (m *tuiModel) record(question domain.Question) {
+	if _, err := recordQuestion(m.state, question); err != nil {
+		m.notice = fmt.Sprintf("⚠️  %v", err)
+	}
+	m.save()
+	m.refreshConversation(true)
+}
+
+// save grava a sessão; o erro aparece no painel em vez de sujar a tela
+func (m *tuiModel) save() {
+	if err := saveSession(m.state); err != nil {
+		m.notice = fmt.Sprintf("⚠️  %v", err)
+	}
+}
+
+// layout distribui o espaço entre conversa, painel lateral e caixa de entrada
+func (m *tuiModel) layout() {
+	sidebar := 0
+	if m.width >= tuiMinWidth {
+		sidebar = tuiSidebarWidth
+	}
+
+	frame := tuiBorder.GetHorizontalFrameSize()
+	m.input.SetWidth(max(m.width-frame, 10))
+	m.conversation.Width = max(m.width-sidebar-frame, 10)
+	// Altura: caixa de entrada com borda, linha de status e borda da conversa
+	m.conversation.Height = max(m.height-(tuiInputHeight+2)-1-tuiBorder.GetVerticalFrameSize(), 3)
+}
+
+// refreshConversation reconstrói o painel de conversa a partir da sessão
+func (m *tuiModel) refreshConversation(scrollToEnd bool) {
+	width := m.conversation.Width
+	if width <= 0 {
+		return
+	}
+	wrap := lipgloss.NewStyle().Width(width)
+	session := m.state.session
+
+	var blocks []string
+	if session.QuestionCount() == 0 && m.request == nil {
+		blocks = append(blocks, tuiMuted.Render(wrap.Render(
+			"Digite sua pergunta abaixo e pressione Enter. Alt+Enter insere uma nova linha; @arquivo anexa um arquivo de texto.")))
+	}
+
+	for _, q := range session.Questions() {
+		blocks = append(blocks, m.questionView(q.ID, q.Timestamp, q.Text, q.Attachments, wrap))
+		switch {
+		case q.Success:
+			header := fmt.Sprintf("🤖 %s · %v", modelDescription(q.Model, session), q.ProcessTime.Round(time.Millisecond))
+			response := q.Response
+			if m.renderer != nil {
+				response = m.renderer.Render(response)
+			} else {
+				response = wrap.Render(response)
+			}
+			blocks = append(blocks, tuiMuted.Render(header)+"\n"+response)
+		default:
+			blocks = append(blocks, tuiError.Render(wrap.Render("❌ "+q.Error)))
+		}
+	}
+
+	if m.choice != nil {
+		q := m.choice.question
+		blocks = append(blocks, m.questionView(session.NextQuestionID(), m.choice.started, q.Text, q.Attachments, wrap))
+		for i, candidate := range m.choice.candidates {
+			if m.renderer != nil {
+				candidate = m.renderer.Render(candidate)
+			} else {
+				candidate = wrap.Render(candidate)
+			}
+			header := fmt.Sprintf("🔀 Geração %d de %d", i+1, len(m.choice.candidates))
+			blocks = append(blocks, tuiTitle.Render(header)+"\n"+candidate)
+		}
+	}
+
+	if m.request != nil {
+		blocks = append(blocks, m.questionView(session.NextQuestionID(), m.request.started, m.request.text, m.request.attachments, wrap))
+		waiting := time.Since(m.request.started).Round(time.Second)
+		blocks = append(blocks, tuiMuted.Render(fmt.Sprintf("⏳ Aguardando resposta... %v (Esc cancela)", waiting)))
+	}
+
+	atBottom := m.conversation.AtBottom()
+	m.conversation.SetContent(strings.Join(blocks, "\n\n"))
+	if scrollToEnd || atBottom {
+		m.conversation.GotoBottom()
+	}
+}
+
+// questionView formata o cabeçalho e o texto de uma pergunta
+func (m *tuiModel) questionView(id int, timestamp time.Time, text string, attachments []domain.Attachment, wrap lipgloss.Style) string {
+	lines := []string{
+		tuiQuestion.Render(fmt.Sprintf("❓ Pergunta %d [%s]", id, timestamp.Format("15:04:05"))),
+		wrap.Render(text),
+	}
+	for _, a := range attachments {
+		lines = append(lines, tuiMuted.Render(wrap.Render("📎 "+a.Describe())))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// modelDescription retorna o nome do modelo que respondeu (a sessão pode ter trocado de modelo)
+func modelDescription(modelID string, session *domain.ChatSession) string {
+	if modelID == "" || modelID == session.ModelID() {
+		return session.ModelName()
+	}
+	if name, ok := domain.SupportedModels[modelID]; ok {
+		return name
+	}
+	return modelID
+}
+
+// sidebarView monta o painel lateral com modelo, contexto, estatísticas e atalhos
+func (m *tuiModel) sidebarView(height int) string {
+	width := tuiSidebarWidth - tuiBorder.GetHorizontalFrameSize()
+	wrap := lipgloss.NewStyle().Width(width)
+	session := m.state.session
+	stats := session.GetStats()
+
+	sections := []string{
+		tuiTitle.Render("🤖 Modelo") + "\n" + wrap.Render(m.state.description) + "\n" +
+			tuiMuted.Render(wrap.Render(m.state.modelImpl.GetModelFamily())),
+		tuiTitle.Render("🧠 Contexto") + "\n" +
+			wrap.Render(strings.TrimPrefix(session.GetContextStatus(), "🧠 Contexto: ")),
+	}
+
+	sessionLines := []string{
+		tuiTitle.Render("📊 Sessão"),
+		fmt.Sprintf("Perguntas: %d (✅ %d ❌ %d)", stats.TotalQuestions, stats.SuccessfulQuestions, stats.FailedQuestions),
+		fmt.Sprintf("Duração: %v", stats.SessionDuration.Round(time.Second)),
+	}
+	if stats.SuccessfulQuestions > 0 {
+		sessionLines = append(sessionLines, fmt.Sprintf("Tempo médio: %v", stats.AverageProcessTime.Round(time.Millisecond)))
+	}
+	if stats.RedactionEvents > 0 {
+		sessionLines = append(sessionLines, fmt.Sprintf("Mascarados: %d", stats.RedactionEvents))
+	}
+	sections = append(sections, strings.Join(sessionLines, "\n"))
+
+	usage := stats.TokenUsage
+	sections = append(sections, tuiTitle.Render("🔢 Tokens")+"\n"+fmt.Sprintf("%d (entrada %d, saída %d)",
+		usage.TotalTokens, usage.PromptTokens, usage.CompletionTokens))
+
+	sections = append(sections, tuiTitle.Render("⌨️  Atalhos")+"\n"+tuiMuted.Render(strings.Join([]string{
+		"Enter       enviar",
+		"Alt+Enter   nova linha",
+		"↑/↓         histórico",
+		"PgUp/PgDn   rolar conversa",
+		"Ctrl+T      contexto",
+		"Esc         cancelar",
+		"Ctrl+C      sair",
+	}, "\n")))
+
+	// Em terminais baixos os últimos itens (atalhos) são cortados, mantendo a borda
+	lines := strings.Split(strings.Join(sections, "\n\n"), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return tuiBorder.Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+func (m *tuiModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	conversation := tuiBorder.Render(m.conversation.View())
+	top := conversation
+	if m.width >= tuiMinWidth {
+		top = lipgloss.JoinHorizontal(lipgloss.Top, conversation, m.sidebarView(m.conversation.Height))
+	}
+
+	status := m.notice
+	if status == "" {
+		status = tuiMuted.Render(fmt.Sprintf("%s · Enter envia · Esc cancela · Ctrl+C sai", m.state.description))
+	}
+	status = lipgloss.NewStyle().MaxWidth(m.width).Render(status)
+
+	return lipgloss.JoinVertical(lipgloss.Left, top, tuiBorder.Render(m.input.View()), status)
+}
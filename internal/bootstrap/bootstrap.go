@@ -0,0 +1,174 @@
+// Package bootstrap reúne a inicialização compartilhada pelos subcomandos:
+// configuração OCI, provider, cliente de inferência e mascaramento de dados.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/generativeaiinference"
+
+	"agente/internal/domain"
+	"agente/internal/infrastructure"
+)
+
+// Options controla o que é exibido durante a inicialização
+type Options struct {
+	ShowConfig bool // Exibe a configuração OCI carregada (em stderr)
+	Quiet      bool // Suprime mensagens de status do mascaramento
+}
+
+// App contém as dependências compartilhadas pelos subcomandos
+type App struct {
+	Config   infrastructure.OCIConfig
+	Client   generativeaiinference.GenerativeAiInferenceClient
+	Redactor *domain.Redactor // nil quando o mascaramento está desativado
+}
+
+// New carrega a configuração e cria o cliente OCI e o mascarador
+func New(opts Options) (*App, error) {
+	cfg, err := infrastructure.ReadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração: %v", err)
+	}
+	if opts.ShowConfig {
+		cfg.PrintConfig()
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := NewRedactor(opts.Quiet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{Config: cfg, Client: client, Redactor: redactor}, nil
+}
+
+// NewProvider cria o provider de configuração a partir do arquivo PEM
+func NewProvider(cfg infrastructure.OCIConfig) (common.ConfigurationProvider, error) {
+	// Ler o conteúdo do arquivo PEM
+	privateKeyContent, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo PEM: %v", err)
+	}
+
+	provider := common.NewRawConfigurationProvider(
+		cfg.TenancyOCID,
+		cfg.UserOCID,
+		cfg.Region,
+		cfg.Fingerprint,
+		string(privateKeyContent),
+		nil,
+	)
+
+	return provider, nil
+}
+
+// NewClient cria o cliente de inferência OCI
+func NewClient(cfg infrastructure.OCIConfig) (generativeaiinference.GenerativeAiInferenceClient, error) {
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return generativeaiinference.GenerativeAiInferenceClient{}, err
+	}
+
+	client, err := generativeaiinference.NewGenerativeAiInferenceClientWithConfigurationProvider(provider)
+	if err != nil {
+		return client, fmt.Errorf("erro ao criar cliente: %v", err)
+	}
+	return client, nil
+}
+
+// NewRedactor monta o mascarador a partir da configuração; retorna nil se desativado
+func NewRedactor(quiet bool) (*domain.Redactor, error) {
+	redactionCfg, err := infrastructure.LoadRedactionConfig()
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração de mascaramento: %v", err)
+	}
+
+	if !redactionCfg.Enabled {
+		if !quiet {
+			fmt.Fprintln(os.Stderr, "⚠️  Mascaramento de dados sensíveis desativado (AGENTE_REDACTION=false)")
+		}
+		return nil, nil
+	}
+
+	redactor, err := domain.NewRedactor(redactionCfg.Detectors, redactionCfg.CustomPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração de mascaramento: %v", err)
+	}
+	redactor.RestoreAnswers = redactionCfg.RestoreAnswers
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "🛡️  Mascaramento ativo: %s\n", strings.Join(redactor.Detectors(), ", "))
+	}
+	return redactor, nil
+}
+
+// ResolveModel valida o modelo e retorna sua implementação e descrição
+func ResolveModel(modelID string) (domain.ModelImplementation, string, error) {
+	description, _, ok := domain.GetModelInfo(modelID)
+	if !ok {
+		if matches := domain.FindModels(modelID); len(matches) > 0 {
+			last := len(matches) - 1
+			suggestion := matches[last]
+			if last > 0 {
+				suggestion = strings.Join(matches[:last], ", ") + " ou " + suggestion
+			}
+			return nil, "", fmt.Errorf("modelo não suportado: %s. Você quis dizer %s?", modelID, suggestion)
+		}
+		return nil, "", fmt.Errorf("modelo não suportado: %s. Use 'agente models' para ver os modelos", modelID)
+	}
+
+	modelImpl := domain.CreateModelImplementation(modelID)
+	if modelImpl == nil {
+		return nil, "", fmt.Errorf("implementação não encontrada para o modelo: %s", modelID)
+	}
+	return modelImpl, description, nil
+}
+
+// NewRedaction inicia um mascaramento por requisição (nil se o mascaramento está desativado)
+func (a *App) NewRedaction() *domain.Redaction {
+	if a.Redactor == nil {
+		return nil
+	}
+	return a.Redactor.NewRedaction()
+}
+
+// RestoreAnswer devolve os valores originais na resposta, se configurado
+func (a *App) RestoreAnswer(redaction *domain.Redaction, answer string) string {
+	if a.Redactor == nil || !a.Redactor.RestoreAnswers {
+		return answer
+	}
+	return redaction.Restore(answer)
+}
+
+// Ask monta a requisição (com contexto, se houver) e retorna as respostas geradas pelo modelo
+func (a *App) Ask(ctx context.Context, modelImpl domain.ModelImplementation, modelID, promptText string, contextQuestions []domain.Question, params domain.GenerationParams) ([]string, domain.TokenUsage, error) {
+	// Criar requisição usando a implementação específica
+	var chatRequest generativeaiinference.ChatRequest
+	if len(contextQuestions) > 0 {
+		chatRequest = modelImpl.CreateChatRequestWithContext(a.Config.TenancyOCID, modelID, promptText, contextQuestions, params)
+	} else {
+		chatRequest = modelImpl.CreateChatRequest(a.Config.TenancyOCID, modelID, promptText, params)
+	}
+
+	resp, err := a.Client.Chat(ctx, chatRequest)
+	if err != nil {
+		return nil, domain.TokenUsage{}, fmt.Errorf("Erro ao processar pergunta: %v", err)
+	}
+
+	// Processar resposta usando a implementação específica
+	usage, _ := modelImpl.ExtractUsage(resp)
+	candidates, err := modelImpl.ProcessResponse(resp)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Erro ao processar resposta: %v", err)
+	}
+	return candidates, usage, nil
+}
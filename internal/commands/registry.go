@@ -0,0 +1,372 @@
+// Package commands implementa o registro de comandos do REPL: nomes, aliases,
+// validação de argumentos, ajuda gerada e sugestões para comandos desconhecidos.
+package commands
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"agente/internal/fuzzy"
+)
+
+// Prefix identifica um comando; entradas sem o prefixo são perguntas para o modelo
+const Prefix = "/"
+
+// ErrExit é retornado por um handler para encerrar o REPL
+var ErrExit = errors.New("encerrar sessão")
+
+// Handler executa um comando com os argumentos já separados
+type Handler func(args []string) error
+
+// Completer sugere valores para o argumento que está sendo digitado
+type Completer func(word string) []string
+
+// Command descreve um comando do REPL
+type Command struct {
+	Name        string
+	Aliases     []string
+	Args        string // Sintaxe dos argumentos exibida na ajuda, ex.: "<nome> <valor>"
+	Description string
+	MinArgs     int
+	MaxArgs     int  // -1 = sem limite
+	FreeText    bool // O último argumento é o resto da linha, sem interpretar aspas (ex.: "d'água")
+	Handler     Handler
+	Complete    Completer // Opcional: completa os argumentos com Tab
+}
+
+// Registry guarda os comandos registrados, na ordem de registro
+type Registry struct {
+	commands []*Command
+	index    map[string]*Command
+}
+
+// NewRegistry cria um registro vazio
+func NewRegistry() *Registry {
+	return &Registry{index: make(map[string]*Command)}
+}
+
+// Register adiciona um comando. Nomes ou aliases repetidos são erro de programação.
+func (r *Registry) Register(cmd Command) {
+	registered := cmd
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		key := strings.ToLower(name)
+		if _, exists := r.index[key]; exists {
+			panic(fmt.Sprintf("comando duplicado: %s", name))
+		}
+		r.index[key] = &registered
+	}
+	r.commands = append(r.commands, &registered)
+}
+
+// Commands retorna os comandos na ordem de registro
+func (r *Registry) Commands() []*Command {
+	return r.commands
+}
+
+// Lookup busca um comando pelo nome ou alias (sem o prefixo)
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.index[strings.ToLower(name)]
+	return cmd, ok
+}
+
+// IsCommand indica se a entrada deve ser tratada como comando.
+// "//texto" é um escape para enviar uma pergunta que começa com "/".
+func IsCommand(input string) bool {
+	return strings.HasPrefix(input, Prefix) && !strings.HasPrefix(input, Prefix+Prefix)
+}
+
+// Unescape remove o escape "//" de perguntas que começam com "/"
+func Unescape(input string) string {
+	if strings.HasPrefix(input, Prefix+Prefix) {
+		return input[len(Prefix):]
+	}
+	return input
+}
+
+// Execute interpreta e executa um comando. A entrada deve começar com o prefixo.
+func (r *Registry) Execute(input string) error {
+	line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), Prefix))
+	name, rest := cutWord(line)
+	if name == "" {
+		return fmt.Errorf("comando vazio. Use %sajuda para ver os comandos", Prefix)
+	}
+
+	cmd, ok := r.Lookup(name)
+	if !ok {
+		if suggestions := r.Suggest(name); len(suggestions) > 0 {
+			return fmt.Errorf("comando desconhecido: %s%s. Você quis dizer %s?", Prefix, name, joinSuggestions(suggestions))
+		}
+		return fmt.Errorf("comando desconhecido: %s%s. Use %sajuda para ver os comandos", Prefix, name, Prefix)
+	}
+
+	var args []string
+	if cmd.FreeText {
+		args = splitFreeText(rest, cmd.MaxArgs)
+	} else {
+		var err error
+		if args, err = SplitArguments(rest); err != nil {
+			return err
+		}
+	}
+	if len(args) < cmd.MinArgs || (cmd.MaxArgs >= 0 && len(args) > cmd.MaxArgs) {
+		return fmt.Errorf("uso: %s", cmd.Syntax())
+	}
+	return cmd.Handler(args)
+}
+
+// Syntax retorna a forma de uso do comando, ex.: "/param <nome> <valor>"
+func (c *Command) Syntax() string {
+	if c.Args == "" {
+		return Prefix + c.Name
+	}
+	return Prefix + c.Name + " " + c.Args
+}
+
+// Help gera o texto de ajuda a partir dos comandos registrados
+func (r *Registry) Help() string {
+	var builder strings.Builder
+
+	for _, cmd := range r.commands {
+		builder.WriteString(fmt.Sprintf("  - %s → %s", cmd.Syntax(), cmd.Description))
+		if len(cmd.Aliases) > 0 {
+			aliases := make([]string, len(cmd.Aliases))
+			for i, alias := range cmd.Aliases {
+				aliases[i] = Prefix + alias
+			}
+			builder.WriteString(fmt.Sprintf(" (%s)", strings.Join(aliases, ", ")))
+		}
+		builder.WriteString("\n")
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// Suggest retorna nomes parecidos com o informado, do mais ao menos parecido
+func (r *Registry) Suggest(name string) []string {
+	name = strings.ToLower(name)
+	maxDistance := 2
+	if len(name) <= 3 {
+		maxDistance = 1
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+
+	// A distância de cada comando é a menor entre o nome e os aliases, na ordem de registro,
+	// para que a sugestão não dependa da ordem do mapa
+	for _, cmd := range r.commands {
+		distance := -1
+		for _, key := range append([]string{cmd.Name}, cmd.Aliases...) {
+			key = strings.ToLower(key)
+			d := fuzzy.Distance(name, key)
+			if strings.HasPrefix(key, name) && len(name) >= 2 {
+				d = 0
+			}
+			if distance < 0 || d < distance {
+				distance = d
+			}
+		}
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{name: cmd.Name, distance: distance})
+		}
+	}
+
+	// Empates ficam na ordem de registro
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	suggestions := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.name)
+		if len(suggestions) == 3 {
+			break
+		}
+	}
+	return suggestions
+}
+
+// Complete retorna a palavra em edição no fim da entrada e as opções para completá-la.
+// A primeira palavra completa nomes de comandos; as demais usam o Completer do comando.
+func (r *Registry) Complete(input string) (string, []string) {
+	if !IsCommand(input) {
+		return "", nil
+	}
+
+	word := input[strings.LastIndexAny(input, " \t")+1:]
+	if !strings.ContainsAny(input, " \t") {
+		var options []string
+		for key := range r.index {
+			if strings.HasPrefix(Prefix+key, strings.ToLower(word)) {
+				options = append(options, Prefix+key)
+			}
+		}
+		sort.Strings(options)
+		return word, options
+	}
+
+	name := strings.Fields(input)[0][len(Prefix):]
+	cmd, ok := r.Lookup(name)
+	if !ok || cmd.Complete == nil {
+		return word, nil
+	}
+	return word, cmd.Complete(word)
+}
+
+// CompleteFrom cria um Completer que sugere os valores com o prefixo digitado
+func CompleteFrom(values func() []string) Completer {
+	return func(word string) []string {
+		var options []string
+		for _, value := range values() {
+			if strings.HasPrefix(value, word) {
+				options = append(options, value)
+			}
+		}
+		return options
+	}
+}
+
+// CompleteFiles sugere caminhos de arquivos e diretórios; diretórios terminam com "/"
+func CompleteFiles(word string) []string {
+	dir, base := filepath.Split(word)
+	entries, err := os.ReadDir(cmp.Or(dir, "."))
+	if err != nil {
+		return nil
+	}
+
+	var options []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			name += string(filepath.Separator)
+		}
+		options = append(options, dir+name)
+	}
+	return options
+}
+
+// SplitArguments separa a entrada em argumentos, respeitando aspas simples e duplas
+func SplitArguments(input string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("aspas não fechadas")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// splitFreeText separa as primeiras count-1 palavras; o que sobra da linha, sem os espaços das
+// pontas, é o último argumento
+func splitFreeText(input string, count int) []string {
+	var args []string
+	rest := strings.TrimLeft(input, " \t")
+	for len(args) < count-1 && rest != "" {
+		var word string
+		word, rest = cutWord(rest)
+		args = append(args, word)
+		rest = strings.TrimLeft(rest, " \t")
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		args = append(args, rest)
+	}
+	return args
+}
+
+// Unquote remove aspas simples ou duplas que envolvem o texto inteiro
+func Unquote(text string) string {
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+// cutWord separa a primeira palavra do restante da linha
+func cutWord(input string) (word, rest string) {
+	if i := strings.IndexAny(input, " \t"); i >= 0 {
+		return input[:i], input[i+1:]
+	}
+	return input, ""
+}
+
+// Assignment é um par nome=valor de comandos como /regenerar e /usar
+type Assignment struct {
+	Name  string
+	Value string
+}
+
+// assignmentStart encontra o início de cada "nome=" na linha
+var assignmentStart = regexp.MustCompile(`(?:^|\s)([\p{L}\p{N}_.-]+)=`)
+
+// ParseAssignments separa "nome=valor nome2=valor com espaços" em pares. O valor vai até o próximo
+// "nome=", mantendo apóstrofos e espaços; aspas em volta do valor inteiro são removidas.
+func ParseAssignments(input string) ([]Assignment, error) {
+	input = strings.TrimSpace(input)
+	matches := assignmentStart.FindAllStringSubmatchIndex(input, -1)
+	if input != "" && (len(matches) == 0 || matches[0][0] != 0) {
+		invalid, _, _ := strings.Cut(input, " ")
+		return nil, fmt.Errorf("argumento inválido '%s': use nome=valor", invalid)
+	}
+
+	assignments := make([]Assignment, 0, len(matches))
+	for i, m := range matches {
+		end := len(input)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		value := Unquote(strings.TrimSpace(input[m[1]:end]))
+		assignments = append(assignments, Assignment{Name: input[m[2]:m[3]], Value: value})
+	}
+	return assignments, nil
+}
+
+func joinSuggestions(suggestions []string) string {
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = Prefix + s
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " ou " + quoted[len(quoted)-1]
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+// testRegistry registra comandos com nomes e aliases parecidos, como os do chat
+func testRegistry() *Registry {
+	r := NewRegistry()
+	for _, cmd := range []Command{
+		{Name: "historico", Aliases: []string{"history", "hist"}},
+		{Name: "limpar", Aliases: []string{"clear"}},
+		{Name: "salvar", Aliases: []string{"save"}},
+		{Name: "sair", Aliases: []string{"exit", "quit"}},
+		{Name: "status", Aliases: []string{"stats"}},
+		{Name: "buscar", Aliases: []string{"search"}},
+	} {
+		r.Register(cmd)
+	}
+	return r
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"histroico", []string{"historico"}},
+		{"histry", []string{"historico"}}, // Pelo alias "history"
+		{"sa", []string{"salvar", "sair"}},
+		{"clea", []string{"limpar"}},
+		{"stat", []string{"status"}},
+		{"sav", []string{"salvar"}},
+		{"xyz", nil},
+	}
+	r := testRegistry()
+	for _, tt := range tests {
+		// A ordem não pode depender da iteração do mapa de aliases
+		for range 20 {
+			if got := r.Suggest(tt.input); !slices.Equal(got, tt.want) {
+				t.Fatalf("Suggest(%q) = %q, esperado %q", tt.input, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSplitArguments(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"a b  c", []string{"a", "b", "c"}, false},
+		{`nome "valor com espaços"`, []string{"nome", "valor com espaços"}, false},
+		{`'aspas "duplas" dentro'`, []string{`aspas "duplas" dentro`}, false},
+		{`x""`, []string{"x"}, false},
+		{`""`, []string{""}, false},
+		{"\tum\tdois ", []string{"um", "dois"}, false},
+		{`"sem fim`, nil, true},
+		{"d'água", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := SplitArguments(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SplitArguments(%q) erro = %v, esperado erro = %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SplitArguments(%q) = %q, esperado %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSplitFreeText(t *testing.T) {
+	tests := []struct {
+		input string
+		count int
+		want  []string
+	}{
+		{"", 1, nil},
+		{"  resposta d'água errada  ", 1, []string{"resposta d'água errada"}},
+		{"system Responda em   português", 2, []string{"system", "Responda em   português"}},
+		{"3", 2, []string{"3"}},
+		{"3   ", 2, []string{"3"}},
+		{`2 "texto com aspas"`, 2, []string{"2", `"texto com aspas"`}},
+	}
+	for _, tt := range tests {
+		if got := splitFreeText(tt.input, tt.count); !slices.Equal(got, tt.want) {
+			t.Errorf("splitFreeText(%q, %d) = %q, esperado %q", tt.input, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []Assignment
+		wantErr bool
+	}{
+		{"", []Assignment{}, false},
+		{"temperature=0.2", []Assignment{{"temperature", "0.2"}}, false},
+		{"system=Seja breve, d'água n=2", []Assignment{{"system", "Seja breve, d'água"}, {"n", "2"}}, false},
+		{`system="a=b c" top_p=0.9`, []Assignment{{"system", "a=b c"}, {"top_p", "0.9"}}, false},
+		{`system="texto entre aspas"`, []Assignment{{"system", "texto entre aspas"}}, false},
+		{"valor solto n=2", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAssignments(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAssignments(%q) erro = %v, esperado erro = %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAssignments(%q) = %q, esperado %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteFreeText(t *testing.T) {
+	r := NewRegistry()
+	var got []string
+	r.Register(Command{Name: "ruim", MaxArgs: 1, FreeText: true, Handler: func(args []string) error {
+		got = args
+		return nil
+	}})
+	if err := r.Execute("/ruim  faltou o \"d'água\" "); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`faltou o "d'água"`}; !slices.Equal(got, want) {
+		t.Errorf("args = %q, esperado %q", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limites dos arquivos anexados a uma pergunta
+const (
+	MaxAttachmentBytes  int64 = 256 * 1024 // Tamanho máximo de cada arquivo
+	MaxAttachmentTokens       = 30000      // Tokens estimados somando todos os anexos da pergunta
+	AttachmentPrefix          = "@"        // Prefixo da sintaxe em linha: "explique @main.go"
+)
+
+// Attachment é um arquivo de texto enviado junto com a pergunta
+type Attachment struct {
+	Name    string `json:"name"` // Caminho como informado pelo usuário
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Tokens  int    `json:"tokens"` // Estimativa de tokens do conteúdo
+	Content string `json:"-"`      // Enviado ao modelo; o histórico guarda apenas nome e hash
+}
+
+// ShortHash retorna o início do hash, suficiente para identificar o conteúdo enviado
+func (a Attachment) ShortHash() string {
+	if len(a.SHA256) < 12 {
+		return a.SHA256
+	}
+	return a.SHA256[:12]
+}
+
+// Describe resume o anexo para exibição, ex.: "main.go (1.2 KB, ~300 tokens, sha256 3f2a...)"
+func (a Attachment) Describe() string {
+	return fmt.Sprintf("%s (%s, ~%d tokens, sha256 %s)", a.Name, formatBytes(a.Size), a.Tokens, a.ShortHash())
+}
+
+// LoadAttachment lê um arquivo de texto, recusando diretórios, binários e arquivos acima do limite
+func LoadAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("erro ao ler arquivo %s: %v", path, err)
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("%s é um diretório", path)
+	}
+	if info.Size() > MaxAttachmentBytes {
+		return Attachment{}, fmt.Errorf("arquivo %s excede o limite de %d KB", path, MaxAttachmentBytes/1024)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("erro ao ler arquivo %s: %v", path, err)
+	}
+	if isBinary(content) {
+		return Attachment{}, fmt.Errorf("arquivo %s parece ser binário; apenas arquivos de texto podem ser anexados", path)
+	}
+
+	text := toUTF8(content)
+	sum := sha256.Sum256(content)
+	return Attachment{
+		Name:    path,
+		Size:    int64(len(content)),
+		SHA256:  hex.EncodeToString(sum[:]),
+		Tokens:  EstimateTokens(text),
+		Content: text,
+	}, nil
+}
+
+// isBinary considera binário o conteúdo com bytes nulos ou com muitos caracteres de controle
+func isBinary(content []byte) bool {
+	sample := content[:min(len(content), 8192)]
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+
+	control := 0
+	for _, b := range sample {
+		if (b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != '\b' && b != 0x1b) || b == 0x7f {
+			control++
+		}
+	}
+	return control*10 > len(sample)
+}
+
+// toUTF8 converte conteúdo em Latin-1 (comum em arquivos antigos no Windows) para UTF-8
+func toUTF8(content []byte) string {
+	if utf8.Valid(content) {
+		return string(content)
+	}
+	runes := make([]rune, len(content))
+	for i, b := range content {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// EstimateTokens estima os tokens de um texto (cerca de 4 caracteres por token)
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// CheckAttachmentLimit verifica se a soma dos anexos cabe no limite de tokens
+func CheckAttachmentLimit(attachments []Attachment) error {
+	total := 0
+	for _, a := range attachments {
+		total += a.Tokens
+	}
+	if total > MaxAttachmentTokens {
+		return fmt.Errorf("anexos somam ~%d tokens, acima do limite de %d", total, MaxAttachmentTokens)
+	}
+	return nil
+}
+
+// FindInlineAttachments retorna os caminhos citados como @arquivo que existem como arquivos.
+// Pontuação final (ex.: "@main.go,") é ignorada; menções que não são arquivos ficam como texto.
+func FindInlineAttachments(text string) []string {
+	var paths []string
+	for _, word := range strings.Fields(text) {
+		if !strings.HasPrefix(word, AttachmentPrefix) || len(word) == len(AttachmentPrefix) {
+			continue
+		}
+
+		path := strings.TrimPrefix(word, AttachmentPrefix)
+		for path != "" {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				paths = append(paths, path)
+				break
+			}
+			trimmed := strings.TrimRight(path, ".,;:!?)\"'")
+			if trimmed == path {
+				break
+			}
+			path = trimmed
+		}
+	}
+	return uniqueStrings(paths)
+}
+
+// BuildPromptWithAttachments acrescenta à pergunta o conteúdo de cada anexo, identificado pelo nome
+func BuildPromptWithAttachments(text string, attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	builder.WriteString(text)
+	for _, a := range attachments {
+		fence := codeFence(a.Content)
+		language := strings.TrimPrefix(filepath.Ext(a.Name), ".")
+
+		builder.WriteString(fmt.Sprintf("\n\nArquivo: %s\n%s%s\n%s", a.Name, fence, language, a.Content))
+		if !strings.HasSuffix(a.Content, "\n") {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fence)
+	}
+	return builder.String()
+}
+
+// codeFence retorna uma cerca ``` maior que qualquer sequência de crases no conteúdo
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// formatBytes formata um tamanho em bytes para exibição
+func formatBytes(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KB", float64(size)/1024)
+}
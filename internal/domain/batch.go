@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BatchRequest é uma linha do arquivo de entrada do modo batch
+type BatchRequest struct {
+	ID     string       `json:"id"`
+	Prompt string       `json:"prompt"`
+	Model  string       `json:"model,omitempty"`
+	System string       `json:"system,omitempty"`
+	Params *BatchParams `json:"params,omitempty"`
+}
+
+// BatchParams contém os parâmetros de geração opcionais de uma linha
+type BatchParams struct {
+	MaxTokens        *int     `json:"max_tokens,omitempty"`
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	TopK             *int     `json:"top_k,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+}
+
+// BatchResult é uma linha do arquivo de saída do modo batch
+type BatchResult struct {
+	ID        string      `json:"id"`
+	Model     string      `json:"model"`
+	Response  string      `json:"response,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	LatencyMs int64       `json:"latency_ms"`
+	Usage     *TokenUsage `json:"usage,omitempty"`
+}
+
+// Apply sobrepõe os parâmetros informados na linha aos parâmetros base
+func (bp *BatchParams) Apply(params GenerationParams) GenerationParams {
+	params = params.Clone()
+	if bp == nil {
+		return params
+	}
+
+	if bp.MaxTokens != nil {
+		params.MaxTokens = *bp.MaxTokens
+	}
+	if bp.Temperature != nil {
+		params.Temperature = *bp.Temperature
+	}
+	if bp.TopP != nil {
+		params.TopP = *bp.TopP
+	}
+	if bp.TopK != nil {
+		params.TopK = *bp.TopK
+	}
+	if bp.Stop != nil {
+		params.StopSequences = append([]string(nil), bp.Stop...)
+	}
+	if bp.FrequencyPenalty != nil {
+		v := *bp.FrequencyPenalty
+		params.FrequencyPenalty = &v
+	}
+	if bp.PresencePenalty != nil {
+		v := *bp.PresencePenalty
+		params.PresencePenalty = &v
+	}
+	if bp.Seed != nil {
+		v := *bp.Seed
+		params.Seed = &v
+	}
+	return params
+}
+
+// ReadBatchRequests lê o arquivo JSONL de entrada. Linhas sem "id" recebem o número da linha.
+func ReadBatchRequests(r io.Reader) ([]BatchRequest, error) {
+	var requests []BatchRequest
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req BatchRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			return nil, fmt.Errorf("linha %d: JSON inválido: %v", lineNumber, err)
+		}
+		if strings.TrimSpace(req.Prompt) == "" {
+			return nil, fmt.Errorf("linha %d: campo \"prompt\" vazio", lineNumber)
+		}
+		// Parâmetros inválidos são recusados antes de iniciar, não quando a linha é processada
+		if err := req.Params.Apply(DefaultGenerationParams()).Validate(); err != nil {
+			return nil, fmt.Errorf("linha %d: \"params\" inválido: %v", lineNumber, err)
+		}
+		if req.ID == "" {
+			req.ID = strconv.Itoa(lineNumber)
+		}
+		if previous, ok := seen[req.ID]; ok {
+			return nil, fmt.Errorf("linha %d: id %q repetido (já usado na linha %d)", lineNumber, req.ID, previous)
+		}
+		seen[req.ID] = lineNumber
+
+		requests = append(requests, req)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler entrada: %v", err)
+	}
+	return requests, nil
+}
+
+// ReadCompletedBatchIDs retorna os IDs já presentes no arquivo de saída.
+// Com skipErrors, linhas que registraram erro não contam como concluídas.
+// Um arquivo inexistente não é erro: nada foi processado ainda.
+func ReadCompletedBatchIDs(path string, skipErrors bool) (map[string]bool, error) {
+	completed := make(map[string]bool)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return completed, nil
+		}
+		return nil, fmt.Errorf("erro ao abrir saída %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var result BatchResult
+		// Linhas incompletas (ex.: execução interrompida durante a escrita) são ignoradas
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil || result.ID == "" {
+			continue
+		}
+		if skipErrors && result.Error != "" {
+			continue
+		}
+		completed[result.ID] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler saída %s: %v", path, err)
+	}
+	return completed, nil
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// O histórico é uma árvore de perguntas: cada pergunta aponta para a anterior do seu ramo (Parent,
+// 0 na primeira). questions guarda o ramo ativo, da primeira à última pergunta, e é dele que o
+// contexto é montado; otherTurns guarda as perguntas dos demais ramos. Regenerar ou editar uma
+// pergunta cria um ramo novo sem apagar o anterior. Os métodos em minúsculas esperam o lock obtido.
+
+// Branch descreve um ramo da conversa, identificado pela sua última pergunta
+type Branch struct {
+	Last    Question // Última pergunta do ramo
+	Length  int      // Quantidade de perguntas, da primeira até a última
+	Diverge int      // Primeira pergunta que não está no ramo ativo (0 no ramo ativo)
+	Active  bool
+}
+
+// NextQuestionID retorna o ID da próxima pergunta; os IDs são únicos entre todos os ramos
+func (cs *ChatSession) NextQuestionID() int {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return max(cs.nextID, 1)
+}
+
+// AllQuestions retorna as perguntas de todos os ramos, ordenadas por ID
+func (cs *ChatSession) AllQuestions() []Question {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.allQuestions()
+}
+
+// FindQuestion busca uma pergunta de qualquer ramo pelo ID
+func (cs *ChatSession) FindQuestion(id int) (Question, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.findQuestion(id)
+}
+
+// IsActive indica se a pergunta está no ramo ativo
+func (cs *ChatSession) IsActive(id int) bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.isActive(id)
+}
+
+// OtherTurnCount retorna quantas perguntas estão fora do ramo ativo
+func (cs *ChatSession) OtherTurnCount() int {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return len(cs.otherTurns)
+}
+
+// Rewind volta o ramo ativo para antes da pergunta id: ela e as seguintes passam para os outros
+// ramos, e a próxima pergunta registrada começa um ramo novo a partir da anterior a ela
+func (cs *ChatSession) Rewind(id int) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	index := slices.IndexFunc(cs.questions, func(q Question) bool { return q.ID == id })
+	if index < 0 {
+		return fmt.Errorf("a pergunta %d não está no ramo ativo", id)
+	}
+
+	cs.linkActive()
+	cs.otherTurns = append(cs.otherTurns, cs.questions[index:]...)
+	cs.questions = slices.Clone(cs.questions[:index])
+	return nil
+}
+
+// SwitchBranch ativa o ramo que passa pela pergunta id, seguindo até a sua pergunta mais recente
+func (cs *ChatSession) SwitchBranch(id int) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if _, ok := cs.findQuestion(id); !ok {
+		return fmt.Errorf("pergunta não encontrada: %d", id)
+	}
+	cs.linkActive()
+
+	// O fim do ramo é a pergunta mais recente abaixo de id (IDs crescem a cada pergunta)
+	children := cs.children()
+	leaf := id
+	for pending := []int{id}; len(pending) > 0; pending = pending[1:] {
+		current := pending[0]
+		pending = append(pending, children[current]...)
+		if len(children[current]) == 0 {
+			leaf = max(leaf, current)
+		}
+	}
+
+	path := cs.path(leaf)
+	all := cs.allQuestions()
+	cs.questions, cs.otherTurns = make([]Question, 0, len(path)), nil
+	for _, q := range all {
+		if slices.Contains(path, q.ID) {
+			continue
+		}
+		cs.otherTurns = append(cs.otherTurns, q)
+	}
+	for _, pathID := range path {
+		for _, q := range all {
+			if q.ID == pathID {
+				cs.questions = append(cs.questions, q)
+			}
+		}
+	}
+	return nil
+}
+
+// Branches lista os ramos da conversa, do mais recente para o mais antigo
+func (cs *ChatSession) Branches() []Branch {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.linkActive()
+	children := cs.children()
+	active := 0
+	if len(cs.questions) > 0 {
+		active = cs.questions[len(cs.questions)-1].ID
+	}
+
+	var branches []Branch
+	for _, q := range cs.allQuestions() {
+		if len(children[q.ID]) > 0 {
+			continue
+		}
+		path := cs.path(q.ID)
+		branch := Branch{Last: q, Length: len(path), Active: q.ID == active}
+		if !branch.Active {
+			for _, id := range path {
+				if !cs.isActive(id) {
+					branch.Diverge = id
+					break
+				}
+			}
+		}
+		branches = append(branches, branch)
+	}
+
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Last.ID > branches[j].Last.ID })
+	return branches
+}
+
+// linkActive garante que as perguntas do ramo ativo apontem para a anterior (sessões salvas antes
+// das ramificações não têm Parent)
+func (cs *ChatSession) linkActive() {
+	for i := range cs.questions {
+		cs.questions[i].Parent = 0
+		if i > 0 {
+			cs.questions[i].Parent = cs.questions[i-1].ID
+		}
+	}
+}
+
+func (cs *ChatSession) allQuestions() []Question {
+	all := append(slices.Clone(cs.questions), cs.otherTurns...)
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
+
+func (cs *ChatSession) findQuestion(id int) (Question, bool) {
+	for _, q := range cs.allQuestions() {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return Question{}, false
+}
+
+func (cs *ChatSession) isActive(id int) bool {
+	return slices.ContainsFunc(cs.questions, func(q Question) bool { return q.ID == id })
+}
+
+// children mapeia cada pergunta (0 = início da conversa) para as perguntas seguintes
+func (cs *ChatSession) children() map[int][]int {
+	children := make(map[int][]int)
+	for _, q := range cs.allQuestions() {
+		children[q.Parent] = append(children[q.Parent], q.ID)
+	}
+	return children
+}
+
+// path retorna os IDs das perguntas da primeira até id
+func (cs *ChatSession) path(id int) []int {
+	parents := make(map[int]int)
+	for _, q := range cs.allQuestions() {
+		parents[q.ID] = q.Parent
+	}
+
+	var path []int
+	for current := id; current != 0 && len(path) <= len(parents); current = parents[current] {
+		path = append(path, current)
+	}
+	slices.Reverse(path)
+	return path
+}
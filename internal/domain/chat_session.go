@@ -1,86 +1,463 @@
 package domain
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
-// ChatSession representa uma sessão de chat com histórico
+// ChatSession representa uma sessão de chat com histórico. Pode ser usada por várias goroutines
+// (comparações, salvamento em segundo plano): o estado só é lido e alterado pelos métodos, que
+// retornam cópias.
 type ChatSession struct {
-	ModelID        string
-	ModelName      string
-	StartTime      time.Time
-	Questions      []Question
-	TotalTime      time.Duration
-	ContextEnabled bool // Controla se o contexto deve ser mantido entre perguntas
+	mu             sync.RWMutex
+	id             string    // Identifica o arquivo da sessão salva; não muda depois de criada
+	startTime      time.Time // Não muda depois de criada
+	modelID        string
+	modelName      string
+	updatedAt      time.Time        // Último salvamento
+	questions      []Question       // Ramo ativo, da primeira à última pergunta
+	otherTurns     []Question       // Perguntas dos demais ramos (ver branches.go)
+	nextID         int              // Próximo ID de pergunta; não reutiliza IDs de perguntas apagadas
+	totalTime      time.Duration    // Mantido apenas para compatibilidade do arquivo
+	contextEnabled bool             // Controla se o contexto deve ser mantido entre perguntas
+	params         GenerationParams // Parâmetros das próximas perguntas
+	redactionCount map[string]int   // Ocorrências mascaradas por detector
+}
+
+// sessionFile é o formato JSON da sessão, usado nos arquivos salvos e na exportação
+type sessionFile struct {
+	ID             string           `json:"id"`
+	ModelID        string           `json:"model_id"`
+	ModelName      string           `json:"model_name"`
+	StartTime      time.Time        `json:"start_time"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Questions      []Question       `json:"questions"`
+	OtherTurns     []Question       `json:"other_turns,omitempty"`
+	NextID         int              `json:"next_id,omitempty"`
+	TotalTime      time.Duration    `json:"total_time"`
+	ContextEnabled bool             `json:"context_enabled"`
+	Params         GenerationParams `json:"params"`
+	RedactionCount map[string]int   `json:"redaction_count,omitempty"`
+}
+
+// MarshalJSON grava o estado da sessão no formato de sessionFile
+func (cs *ChatSession) MarshalJSON() ([]byte, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return json.Marshal(sessionFile{
+		ID:             cs.id,
+		ModelID:        cs.modelID,
+		ModelName:      cs.modelName,
+		StartTime:      cs.startTime,
+		UpdatedAt:      cs.updatedAt,
+		Questions:      cs.questions,
+		OtherTurns:     cs.otherTurns,
+		NextID:         cs.nextID,
+		TotalTime:      cs.totalTime,
+		ContextEnabled: cs.contextEnabled,
+		Params:         cs.params,
+		RedactionCount: cs.redactionCount,
+	})
+}
+
+// UnmarshalJSON lê uma sessão salva; arquivos antigos não têm next_id, calculado a partir dos IDs
+func (cs *ChatSession) UnmarshalJSON(data []byte) error {
+	var file sessionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.id, cs.startTime = file.ID, file.StartTime
+	cs.modelID, cs.modelName = file.ModelID, file.ModelName
+	cs.updatedAt = file.UpdatedAt
+	cs.questions, cs.otherTurns = file.Questions, file.OtherTurns
+	cs.totalTime = file.TotalTime
+	cs.contextEnabled = file.ContextEnabled
+	cs.params = file.Params
+	cs.redactionCount = file.RedactionCount
+	if cs.questions == nil {
+		cs.questions = make([]Question, 0)
+	}
+	if cs.redactionCount == nil {
+		cs.redactionCount = make(map[string]int)
+	}
+	cs.nextID = max(file.NextID, 1)
+	for _, q := range cs.allQuestions() {
+		cs.nextID = max(cs.nextID, q.ID+1)
+	}
+	return nil
 }
 
 // Question representa uma pergunta e sua resposta
 type Question struct {
-	ID          int
-	Text        string
-	Response    string
-	Timestamp   time.Time
-	ProcessTime time.Duration
-	Success     bool
-	Error       string
+	ID            int              `json:"id"`               // Único entre todos os ramos da sessão
+	Parent        int              `json:"parent,omitempty"` // Pergunta anterior no ramo (0 na primeira)
+	Model         string           `json:"model,omitempty"`  // Modelo que respondeu; a sessão pode trocar de modelo
+	Text          string           `json:"text"`
+	Prompt        string           `json:"prompt,omitempty"`      // Texto enviado ao modelo quando difere de Text (ex.: com anexos)
+	Attachments   []Attachment     `json:"attachments,omitempty"` // Arquivos enviados com a pergunta (nome e hash)
+	Commands      []ShellCommand   `json:"commands,omitempty"`    // Comandos (!comando) cuja saída acompanhou a pergunta
+	Recalled      []string         `json:"recalled,omitempty"`    // Conversas anteriores (/reusar) enviadas com a pergunta
+	Response      string           `json:"response,omitempty"`
+	Timestamp     time.Time        `json:"timestamp"`
+	ProcessTime   time.Duration    `json:"process_time"`
+	Success       bool             `json:"success"`
+	Error         string           `json:"error,omitempty"`
+	Params        GenerationParams `json:"params"`                   // Parâmetros usados na geração (inclui a seed, se fixada)
+	Candidates    int              `json:"candidates,omitempty"`     // Quantidade de respostas geradas quando n > 1
+	Usage         TokenUsage       `json:"usage"`                    // Consumo de tokens (zerado se o modelo não informar)
+	Source        string           `json:"source,omitempty"`         // Arquivo de origem, quando importada de outra ferramenta
+	Pinned        bool             `json:"pinned,omitempty"`         // Sempre enviada no contexto (/fixar)
+	Excluded      bool             `json:"excluded,omitempty"`       // Nunca enviada no contexto (/excluir)
+	Rating        int              `json:"rating,omitempty"`         // RatingGood (/bom), RatingBad (/ruim) ou 0
+	RatingComment string           `json:"rating_comment,omitempty"` // Comentário da avaliação
+	Tags          []string         `json:"tags,omitempty"`           // Tags (/tag), usadas para filtrar o dataset
+}
+
+// SentText retorna o texto efetivamente enviado ao modelo, usado ao montar o contexto
+func (q Question) SentText() string {
+	if q.Prompt != "" {
+		return q.Prompt
+	}
+	return q.Text
 }
 
 // NewChatSession cria uma nova sessão de chat
 func NewChatSession(modelID, modelName string) *ChatSession {
+	start := time.Now()
+	return RestoreChatSession(NewSessionID(start), start, modelID, modelName)
+}
+
+// RestoreChatSession cria uma sessão vazia com ID e início já conhecidos (ex.: recriada a partir
+// do diário)
+func RestoreChatSession(id string, start time.Time, modelID, modelName string) *ChatSession {
 	return &ChatSession{
-		ModelID:        modelID,
-		ModelName:      modelName,
-		StartTime:      time.Now(),
-		Questions:      make([]Question, 0),
-		ContextEnabled: true, // Contexto ativado por padrão
+		id:             id,
+		startTime:      start,
+		modelID:        modelID,
+		modelName:      modelName,
+		questions:      make([]Question, 0),
+		nextID:         1,
+		contextEnabled: true, // Contexto ativado por padrão
+		params:         DefaultGenerationParams(),
+		redactionCount: make(map[string]int),
+	}
+}
+
+// DecodeChatSession lê uma sessão salva; id é usado quando o arquivo não o informa
+func DecodeChatSession(data []byte, id string) (*ChatSession, error) {
+	var session ChatSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	if session.id == "" {
+		session.id = id
+	}
+	return &session, nil
+}
+
+// ID retorna o identificador da sessão, usado no nome do arquivo salvo
+func (cs *ChatSession) ID() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.id
+}
+
+// StartTime retorna o início da sessão
+func (cs *ChatSession) StartTime() time.Time {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.startTime
+}
+
+// ModelID retorna o modelo atual da sessão
+func (cs *ChatSession) ModelID() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.modelID
+}
+
+// ModelName retorna a descrição do modelo atual da sessão
+func (cs *ChatSession) ModelName() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.modelName
+}
+
+// SetModel troca o modelo usado nas próximas perguntas
+func (cs *ChatSession) SetModel(modelID, modelName string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.modelID, cs.modelName = modelID, modelName
+}
+
+// UpdatedAt retorna o horário do último salvamento
+func (cs *ChatSession) UpdatedAt() time.Time {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.updatedAt
+}
+
+// MarkSaved registra o horário do salvamento
+func (cs *ChatSession) MarkSaved(at time.Time) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.updatedAt = at
+}
+
+// Questions retorna uma cópia do ramo ativo, da primeira à última pergunta
+func (cs *ChatSession) Questions() []Question {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return slices.Clone(cs.questions)
+}
+
+// QuestionCount retorna a quantidade de perguntas do ramo ativo
+func (cs *ChatSession) QuestionCount() int {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return len(cs.questions)
+}
+
+// LastQuestion retorna a última pergunta do ramo ativo
+func (cs *ChatSession) LastQuestion() (Question, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	if len(cs.questions) == 0 {
+		return Question{}, false
+	}
+	return cs.questions[len(cs.questions)-1], true
+}
+
+// Params retorna uma cópia dos parâmetros de geração das próximas perguntas
+func (cs *ChatSession) Params() GenerationParams {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.params.Clone()
+}
+
+// SetParam altera um parâmetro de geração (ver GenerationParams.Set)
+func (cs *ChatSession) SetParam(name, value string) error {
+	return cs.UpdateParams(func(p *GenerationParams) error { return p.Set(name, value) })
+}
+
+// UpdateParams altera os parâmetros de geração; nada muda se update retornar erro
+func (cs *ChatSession) UpdateParams(update func(*GenerationParams) error) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	params := cs.params.Clone()
+	if err := update(&params); err != nil {
+		return err
+	}
+	cs.params = params
+	return nil
+}
+
+// RedactionCount retorna uma cópia das ocorrências mascaradas por detector
+func (cs *ChatSession) RedactionCount() map[string]int {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return maps.Clone(cs.redactionCount)
+}
+
+// NewSessionID gera o identificador a partir do horário de início, ex.: "20250314-153012-a1b2".
+// O sufixo aleatório evita colisões entre sessões iniciadas no mesmo segundo.
+func NewSessionID(start time.Time) string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%s", start.Format("20060102-150405"), hex.EncodeToString(suffix))
+}
+
+// SessionSummary resume uma sessão salva para listagem
+type SessionSummary struct {
+	ID            string
+	ModelName     string
+	StartTime     time.Time
+	UpdatedAt     time.Time
+	Questions     int
+	FirstQuestion string
+}
+
+// Summary retorna o resumo da sessão, com a primeira pergunta em uma linha
+func (cs *ChatSession) Summary() SessionSummary {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	summary := SessionSummary{
+		ID:        cs.id,
+		ModelName: cs.modelName,
+		StartTime: cs.startTime,
+		UpdatedAt: cs.updatedAt,
+		Questions: len(cs.questions),
+	}
+	if len(cs.questions) > 0 {
+		summary.FirstQuestion = strings.Join(strings.Fields(cs.questions[0].Text), " ")
 	}
+	return summary
 }
 
 // AddQuestion adiciona uma pergunta ao histórico
 func (cs *ChatSession) AddQuestion(text, response string, processTime time.Duration, success bool, errorMsg string) {
-	question := Question{
-		ID:          len(cs.Questions) + 1,
+	cs.RecordQuestion(Question{
+		Model:       cs.ModelID(),
 		Text:        text,
 		Response:    response,
-		Timestamp:   time.Now(),
 		ProcessTime: processTime,
 		Success:     success,
 		Error:       errorMsg,
+		Params:      cs.Params(),
+	})
+}
+
+// RecordQuestion adiciona ao fim do ramo ativo uma pergunta já preenchida, atribuindo ID e horário
+func (cs *ChatSession) RecordQuestion(question Question) Question {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.recordQuestion(question)
+}
+
+// recordQuestion é RecordQuestion com o lock já obtido
+func (cs *ChatSession) recordQuestion(question Question) Question {
+	question.ID = max(cs.nextID, 1)
+	cs.nextID = question.ID + 1
+	question.Parent = 0
+	if len(cs.questions) > 0 {
+		question.Parent = cs.questions[len(cs.questions)-1].ID
+	}
+	question.Timestamp = time.Now()
+
+	cs.questions = append(cs.questions, question)
+	return question
+}
+
+// RestoreQuestions acrescenta perguntas já registradas (recuperadas do diário da sessão) que ainda
+// não estão no histórico, mantendo ID, ramo e horário. As perguntas apagadas com /esquecer
+// (forgotten) não voltam e também são removidas do histórico, se o apagamento não chegou a ser
+// salvo. Retorna quantas foram acrescentadas.
+func (cs *ChatSession) RestoreQuestions(questions []Question, forgotten []int) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	parents := make(map[int]int, len(questions))
+	for _, q := range questions {
+		parents[q.ID] = q.Parent
+	}
+	for _, id := range forgotten {
+		if q, err := cs.forget(id); err == nil {
+			parents[id] = q.Parent
+		}
+	}
+
+	restored := 0
+	for _, q := range questions {
+		if _, ok := cs.findQuestion(q.ID); ok || q.ID < 1 || slices.Contains(forgotten, q.ID) {
+			continue
+		}
+		// Como em Forget, a pergunta seguinte a uma apagada passa a apontar para a anterior a ela
+		for slices.Contains(forgotten, q.Parent) {
+			parent, ok := parents[q.Parent]
+			if !ok {
+				break
+			}
+			q.Parent = parent
+		}
+		last := 0
+		if len(cs.questions) > 0 {
+			last = cs.questions[len(cs.questions)-1].ID
+		}
+		if q.Parent == last {
+			cs.questions = append(cs.questions, q)
+		} else {
+			cs.otherTurns = append(cs.otherTurns, q)
+		}
+		cs.nextID = max(cs.nextID, q.ID+1)
+		restored++
+	}
+	return restored
+}
+
+// LastSuccessfulQuestion retorna a última pergunta respondida com sucesso (aceita sessão nil)
+func (cs *ChatSession) LastSuccessfulQuestion() (Question, bool) {
+	if cs == nil {
+		return Question{}, false
+	}
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	for i := len(cs.questions) - 1; i >= 0; i-- {
+		if cs.questions[i].Success {
+			return cs.questions[i], true
+		}
 	}
+	return Question{}, false
+}
 
-	cs.Questions = append(cs.Questions, question)
+// AddRedactionEvents acumula as ocorrências mascaradas antes de um envio
+func (cs *ChatSession) AddRedactionEvents(events map[string]int) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.redactionCount == nil {
+		cs.redactionCount = make(map[string]int)
+	}
+	for detector, n := range events {
+		cs.redactionCount[detector] += n
+	}
 }
 
 // GetStats retorna estatísticas da sessão
 func (cs *ChatSession) GetStats() SessionStats {
-	totalQuestions := len(cs.Questions)
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	totalQuestions := len(cs.questions)
 	successfulQuestions := 0
+	timedQuestions := 0 // Importadas não têm tempo de processamento
 	totalProcessTime := time.Duration(0)
+	var usage TokenUsage
 
-	for _, q := range cs.Questions {
+	for _, q := range cs.questions {
 		if q.Success {
 			successfulQuestions++
+			if q.Source == "" {
+				timedQuestions++
+			}
 		}
 		totalProcessTime += q.ProcessTime
+		usage.PromptTokens += q.Usage.PromptTokens
+		usage.CompletionTokens += q.Usage.CompletionTokens
+		usage.TotalTokens += q.Usage.TotalTokens
 	}
 
-	sessionDuration := time.Since(cs.StartTime)
+	sessionDuration := time.Since(cs.startTime)
+
+	redactionEvents := 0
+	for _, n := range cs.redactionCount {
+		redactionEvents += n
+	}
 
 	return SessionStats{
 		TotalQuestions:      totalQuestions,
 		SuccessfulQuestions: successfulQuestions,
 		FailedQuestions:     totalQuestions - successfulQuestions,
 		SessionDuration:     sessionDuration,
-		AverageProcessTime:  calculateAverageTime(totalProcessTime, successfulQuestions),
-		ModelUsed:           cs.ModelName,
+		AverageProcessTime:  calculateAverageTime(totalProcessTime, timedQuestions),
+		ModelUsed:           cs.modelName,
+		RedactionEvents:     redactionEvents,
+		TokenUsage:          usage,
 	}
 }
 
 // ShowHistory exibe o histórico de perguntas
 func (cs *ChatSession) ShowHistory() {
-	if len(cs.Questions) == 0 {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	if len(cs.questions) == 0 {
 		fmt.Println("📝 Nenhuma pergunta foi feita ainda nesta sessão.")
 		return
 	}
@@ -88,12 +465,12 @@ func (cs *ChatSession) ShowHistory() {
 	fmt.Println("\n" + strings.Repeat("=", 70))
 	fmt.Println("📚 HISTÓRICO DA SESSÃO")
 	fmt.Println(strings.Repeat("=", 70))
-	fmt.Printf("🤖 Modelo: %s\n", cs.ModelName)
-	fmt.Printf("⏰ Iniciado em: %s\n", cs.StartTime.Format("15:04:05"))
-	fmt.Printf("📊 Total de perguntas: %d\n", len(cs.Questions))
+	fmt.Printf("🤖 Modelo: %s\n", cs.modelName)
+	fmt.Printf("⏰ Iniciado em: %s\n", cs.startTime.Format("15:04:05"))
+	fmt.Printf("📊 Total de perguntas: %d\n", len(cs.questions))
 	fmt.Println(strings.Repeat("-", 70))
 
-	for _, q := range cs.Questions {
+	for i, q := range cs.questions {
 		status := "✅"
 		if !q.Success {
 			status = "❌"
@@ -101,6 +478,40 @@ func (cs *ChatSession) ShowHistory() {
 
 		fmt.Printf("\n%s Pergunta %d [%s]:\n", status, q.ID, q.Timestamp.Format("15:04:05"))
 		fmt.Printf("❓ %s\n", q.Text)
+		if q.Model != "" && q.Model != cs.modelID {
+			fmt.Printf("🤖 Modelo: %s\n", q.Model)
+		}
+		for _, a := range q.Attachments {
+			fmt.Printf("📎 %s\n", a.Describe())
+		}
+		for _, c := range q.Commands {
+			fmt.Printf("🐚 %s\n", c.Describe())
+		}
+		for _, r := range q.Recalled {
+			fmt.Printf("🔁 %s\n", r)
+		}
+		if q.Source != "" {
+			fmt.Printf("📥 Importada de %s\n", q.Source)
+		}
+		if q.Pinned {
+			fmt.Println("📌 Fixada no contexto")
+		}
+		if q.Excluded {
+			fmt.Println("🚫 Fora do contexto")
+		}
+		switch q.Rating {
+		case RatingGood:
+			fmt.Println("👍 Boa resposta")
+		case RatingBad:
+			if q.RatingComment != "" {
+				fmt.Printf("👎 Resposta ruim: %s\n", q.RatingComment)
+			} else {
+				fmt.Println("👎 Resposta ruim")
+			}
+		}
+		if len(q.Tags) > 0 {
+			fmt.Printf("🏷️  %s\n", strings.Join(q.Tags, ", "))
+		}
 
 		if q.Success {
 			// Truncar resposta se muito longa
@@ -109,17 +520,28 @@ func (cs *ChatSession) ShowHistory() {
 				response = response[:200] + "..."
 			}
 			fmt.Printf("💬 %s\n", response)
-			fmt.Printf("⚡ Tempo de processamento: %v\n", q.ProcessTime.Round(time.Millisecond))
+			if q.Source == "" {
+				fmt.Printf("⚡ Tempo de processamento: %v\n", q.ProcessTime.Round(time.Millisecond))
+			}
+			if q.Params.Seed != nil {
+				fmt.Printf("🎲 Seed: %d\n", *q.Params.Seed)
+			}
+			if q.Candidates > 1 {
+				fmt.Printf("🔀 Escolhida entre %d gerações\n", q.Candidates)
+			}
 		} else {
 			fmt.Printf("💥 Erro: %s\n", q.Error)
 		}
 
-		if q.ID < len(cs.Questions) {
+		if i < len(cs.questions)-1 {
 			fmt.Println(strings.Repeat("-", 50))
 		}
 	}
 
 	fmt.Println(strings.Repeat("=", 70))
+	if len(cs.otherTurns) > 0 {
+		fmt.Printf("🌿 %d pergunta(s) em outros ramos da conversa (veja /ramos)\n", len(cs.otherTurns))
+	}
 }
 
 // ShowStats exibe estatísticas da sessão
@@ -140,6 +562,16 @@ func (cs *ChatSession) ShowStats() {
 		fmt.Printf("📈 Taxa de sucesso: %.1f%%\n", successRate)
 		fmt.Printf("⚡ Tempo médio por pergunta: %v\n", stats.AverageProcessTime.Round(time.Millisecond))
 	}
+	if stats.TokenUsage.TotalTokens > 0 {
+		fmt.Printf("🔢 Tokens: %d (entrada %d, saída %d)\n", stats.TokenUsage.TotalTokens, stats.TokenUsage.PromptTokens, stats.TokenUsage.CompletionTokens)
+	}
+
+	if stats.RedactionEvents > 0 {
+		fmt.Printf("🛡️  Dados sensíveis mascarados: %d\n", stats.RedactionEvents)
+		for detector, n := range cs.RedactionCount() {
+			fmt.Printf("   • %s: %d\n", detector, n)
+		}
+	}
 
 	fmt.Println(strings.Repeat("=", 60))
 }
@@ -152,6 +584,8 @@ type SessionStats struct {
 	SessionDuration     time.Duration
 	AverageProcessTime  time.Duration
 	ModelUsed           string
+	RedactionEvents     int
+	TokenUsage          TokenUsage // Soma das perguntas cujo modelo informou o consumo
 }
 
 // calculateAverageTime calcula o tempo médio
@@ -164,34 +598,59 @@ func calculateAverageTime(total time.Duration, count int) time.Duration {
 
 // GetLastQuestions retorna as últimas N perguntas
 func (cs *ChatSession) GetLastQuestions(n int) []Question {
-	if len(cs.Questions) == 0 {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	if len(cs.questions) == 0 {
 		return []Question{}
 	}
 
-	start := len(cs.Questions) - n
+	start := len(cs.questions) - n
 	if start < 0 {
 		start = 0
 	}
 
-	return cs.Questions[start:]
+	return slices.Clone(cs.questions[start:])
 }
 
 // ExportHistory exporta o histórico em formato texto
 func (cs *ChatSession) ExportHistory() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
 	var builder strings.Builder
 
-	builder.WriteString(fmt.Sprintf("=== SESSÃO DE CHAT - %s ===\n", cs.StartTime.Format("02/01/2006 15:04:05")))
-	builder.WriteString(fmt.Sprintf("Modelo: %s\n", cs.ModelName))
-	builder.WriteString(fmt.Sprintf("Total de perguntas: %d\n\n", len(cs.Questions)))
+	builder.WriteString(fmt.Sprintf("=== SESSÃO DE CHAT - %s ===\n", cs.startTime.Format("02/01/2006 15:04:05")))
+	builder.WriteString(fmt.Sprintf("Modelo: %s\n", cs.modelName))
+	builder.WriteString(fmt.Sprintf("Total de perguntas: %d\n\n", len(cs.questions)))
 
-	for _, q := range cs.Questions {
+	for _, q := range cs.questions {
 		builder.WriteString(fmt.Sprintf("PERGUNTA %d [%s]:\n", q.ID, q.Timestamp.Format("15:04:05")))
 		builder.WriteString(fmt.Sprintf("%s\n\n", q.Text))
+		for _, a := range q.Attachments {
+			builder.WriteString(fmt.Sprintf("ANEXO: %s\n", a.Describe()))
+		}
+		for _, c := range q.Commands {
+			builder.WriteString(fmt.Sprintf("COMANDO: %s\n", c.Describe()))
+		}
+		for _, r := range q.Recalled {
+			builder.WriteString(fmt.Sprintf("REUTILIZADA: %s\n", r))
+		}
+		if q.Source != "" {
+			builder.WriteString(fmt.Sprintf("IMPORTADA DE: %s\n", q.Source))
+		}
+		if len(q.Attachments) > 0 || len(q.Commands) > 0 || len(q.Recalled) > 0 || q.Source != "" {
+			builder.WriteString("\n")
+		}
 
 		if q.Success {
 			builder.WriteString("RESPOSTA:\n")
 			builder.WriteString(fmt.Sprintf("%s\n", q.Response))
-			builder.WriteString(fmt.Sprintf("(Processado em %v)\n\n", q.ProcessTime.Round(time.Millisecond)))
+			if q.Source == "" {
+				builder.WriteString(fmt.Sprintf("(Processado em %v)\n", q.ProcessTime.Round(time.Millisecond)))
+			}
+			if q.Params.Seed != nil {
+				builder.WriteString(fmt.Sprintf("(Seed: %d)\n", *q.Params.Seed))
+			}
+			builder.WriteString("\n")
 		} else {
 			builder.WriteString(fmt.Sprintf("ERRO: %s\n\n", q.Error))
 		}
@@ -204,22 +663,28 @@ func (cs *ChatSession) ExportHistory() string {
 
 // ToggleContext alterna o estado do contexto
 func (cs *ChatSession) ToggleContext() {
-	cs.ContextEnabled = !cs.ContextEnabled
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.contextEnabled = !cs.contextEnabled
 }
 
 // SetContext define o estado do contexto
 func (cs *ChatSession) SetContext(enabled bool) {
-	cs.ContextEnabled = enabled
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.contextEnabled = enabled
 }
 
 // IsContextEnabled retorna se o contexto está ativado
 func (cs *ChatSession) IsContextEnabled() bool {
-	return cs.ContextEnabled
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.contextEnabled
 }
 
 // GetContextStatus retorna uma string descrevendo o status do contexto
 func (cs *ChatSession) GetContextStatus() string {
-	if cs.ContextEnabled {
+	if cs.IsContextEnabled() {
 		return "🧠 Contexto: ATIVADO - O modelo lembrará das perguntas anteriores"
 	}
 	return "🧠 Contexto: DESATIVADO - Cada pergunta será independente"
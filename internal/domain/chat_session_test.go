@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestChatSessionConcurrentAccess exercita a sessão como o chat faz (salvamento em segundo plano,
+// comparações, comandos); rode com go test -race
+func TestChatSessionConcurrentAccess(t *testing.T) {
+	session := NewChatSession(ModelMetaLlama33_70B, "Llama")
+	for i := range 10 {
+		session.RecordQuestion(Question{Text: fmt.Sprintf("pergunta %d", i), Success: true})
+	}
+
+	const rounds = 200
+	var wg sync.WaitGroup
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				f(i)
+			}
+		}()
+	}
+
+	run(func(i int) {
+		session.RecordQuestion(Question{Text: fmt.Sprintf("nova %d", i), Success: true})
+	})
+	run(func(i int) {
+		for _, q := range session.Questions() {
+			_ = q.Text
+		}
+	})
+	run(func(i int) {
+		if _, err := json.Marshal(session); err != nil {
+			t.Errorf("MarshalJSON: %v", err)
+		}
+	})
+	run(func(i int) {
+		// Perguntas já apagadas retornam erro; basta não corromper o histórico
+		session.Forget(i%10 + 1)
+	})
+	run(func(i int) {
+		session.SetPinned(i%20+1, i%2 == 0)
+	})
+	run(func(i int) {
+		if next := session.NextQuestionID(); next < 11 {
+			t.Errorf("NextQuestionID = %d, esperado pelo menos 11", next)
+		}
+		_, _ = session.ID(), session.StartTime()
+	})
+	wg.Wait()
+
+	questions := session.Questions()
+	if len(questions) != rounds {
+		t.Fatalf("%d perguntas no histórico, esperado %d (as 10 iniciais foram apagadas)", len(questions), rounds)
+	}
+	seen := make(map[int]bool)
+	for i, q := range questions {
+		if seen[q.ID] {
+			t.Fatalf("ID %d repetido", q.ID)
+		}
+		seen[q.ID] = true
+		if i > 0 && q.Parent != questions[i-1].ID {
+			t.Fatalf("pergunta %d aponta para %d, esperado %d", q.ID, q.Parent, questions[i-1].ID)
+		}
+	}
+	if next := session.NextQuestionID(); next != 10+rounds+1 {
+		t.Fatalf("NextQuestionID = %d, esperado %d", next, 10+rounds+1)
+	}
+}
+
+// TestChatSessionConcurrentSaveAndRecord grava a sessão enquanto perguntas são registradas e
+// confere que cada arquivo gravado é lido de volta com o mesmo ID e início
+func TestChatSessionConcurrentSaveAndRecord(t *testing.T) {
+	session := NewChatSession(ModelMetaLlama33_70B, "Llama")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			session.RecordQuestion(Question{Text: fmt.Sprintf("pergunta %d", i), Success: true})
+		}
+	}()
+
+	for range 100 {
+		data, err := json.Marshal(session)
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		loaded, err := DecodeChatSession(data, "")
+		if err != nil {
+			t.Fatalf("DecodeChatSession: %v", err)
+		}
+		if loaded.ID() != session.ID() || !loaded.StartTime().Equal(session.StartTime()) {
+			t.Fatalf("sessão lida com ID %s (%v), esperado %s (%v)",
+				loaded.ID(), loaded.StartTime(), session.ID(), session.StartTime())
+		}
+	}
+	wg.Wait()
+}
+
+func TestDecodeChatSessionFallbackID(t *testing.T) {
+	start := time.Date(2025, 1, 1, 14, 30, 0, 0, time.UTC)
+	session := RestoreChatSession("", start, ModelMetaLlama33_70B, "Llama")
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := DecodeChatSession(data, "20250101-143000-a1b2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ID() != "20250101-143000-a1b2" {
+		t.Errorf("ID = %q, esperado o do nome do arquivo", loaded.ID())
+	}
+	if !loaded.StartTime().Equal(start) {
+		t.Errorf("StartTime = %v, esperado %v", loaded.StartTime(), start)
+	}
+}
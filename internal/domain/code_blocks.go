@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CodeBlock é um bloco de código cercado por ``` (ou ~~~) em uma resposta
+type CodeBlock struct {
+	Index    int // Posição na resposta, a partir de 1
+	Language string
+	Content  string
+}
+
+// codeExtensions mapeia a linguagem do bloco para a extensão usada ao salvar
+var codeExtensions = map[string]string{
+	"go": "go", "golang": "go",
+	"python": "py", "py": "py", "python3": "py",
+	"javascript": "js", "js": "js", "jsx": "jsx", "typescript": "ts", "ts": "ts", "tsx": "tsx",
+	"java": "java", "kotlin": "kt", "kt": "kt", "csharp": "cs", "cs": "cs", "c#": "cs",
+	"c": "c", "cpp": "cpp", "c++": "cpp", "h": "h", "hpp": "hpp",
+	"rust": "rs", "rs": "rs", "ruby": "rb", "rb": "rb", "php": "php", "swift": "swift",
+	"bash": "sh", "sh": "sh", "shell": "sh", "zsh": "sh", "powershell": "ps1", "ps1": "ps1",
+	"sql": "sql", "json": "json", "yaml": "yaml", "yml": "yaml", "toml": "toml", "xml": "xml",
+	"html": "html", "css": "css", "markdown": "md", "md": "md", "dockerfile": "Dockerfile",
+}
+
+// Extension retorna a extensão de arquivo para a linguagem do bloco ("txt" se desconhecida)
+func (b CodeBlock) Extension() string {
+	if ext, ok := codeExtensions[strings.ToLower(b.Language)]; ok {
+		return ext
+	}
+	return "txt"
+}
+
+// Lines retorna a quantidade de linhas do bloco
+func (b CodeBlock) Lines() int {
+	return strings.Count(b.Content, "\n") + 1
+}
+
+// ExtractCodeBlocks retorna os blocos de código cercados da resposta, na ordem em que aparecem.
+// Um bloco sem cerca de fechamento vai até o fim da resposta.
+func ExtractCodeBlocks(markdown string) []CodeBlock {
+	var blocks []CodeBlock
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		fence, language, ok := openingFence(lines[i])
+		if !ok {
+			continue
+		}
+
+		indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+		var content []string
+		for i++; i < len(lines); i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				break
+			}
+			// Remove o recuo da cerca (ex.: bloco dentro de um item de lista)
+			line := lines[i]
+			line = line[min(indent, len(line)-len(strings.TrimLeft(line, " "))):]
+			content = append(content, line)
+		}
+
+		blocks = append(blocks, CodeBlock{
+			Index:    len(blocks) + 1,
+			Language: language,
+			Content:  strings.Join(content, "\n"),
+		})
+	}
+	return blocks
+}
+
+// openingFence reconhece a abertura de um bloco, ex.: "```go" ou "~~~"
+func openingFence(line string) (fence, language string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, marker := range []string{"```", "~~~"} {
+		if !strings.HasPrefix(trimmed, marker) {
+			continue
+		}
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, marker[:1]))
+		info := strings.TrimSpace(trimmed[n:])
+		if marker == "```" && strings.Contains(info, "`") {
+			return "", "", false
+		}
+		if fields := strings.Fields(info); len(fields) > 0 {
+			language = strings.Trim(fields[0], "{}.")
+		}
+		return trimmed[:n], language, true
+	}
+	return "", "", false
+}
+
+// CodeBlockFileName gera o nome usado ao salvar automaticamente, ex.: "resposta3_1.go"
+func CodeBlockFileName(questionID int, block CodeBlock) string {
+	return fmt.Sprintf("resposta%d_%d.%s", questionID, block.Index, block.Extension())
+}
+
+// WriteCodeBlock grava o conteúdo do bloco, criando os diretórios necessários
+func WriteCodeBlock(path string, block CodeBlock) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("erro ao criar diretório %s: %v", dir, err)
+		}
+	}
+
+	content := block.Content
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	return nil
+}
+
+// AvailablePath retorna o caminho, ou uma variação com sufixo numérico se o arquivo já existir
+func AvailablePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, n, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -11,78 +11,101 @@ import (
 type CohereImplementation struct{}
 
 // CreateChatRequest cria uma requisição de chat específica para modelos Cohere
-func (c *CohereImplementation) CreateChatRequest(compartmentId, modelId, inputText string) generativeaiinference.ChatRequest {
-	return generativeaiinference.ChatRequest{
-		ChatDetails: generativeaiinference.ChatDetails{
-			CompartmentId: common.String(compartmentId),
-			ServingMode: generativeaiinference.OnDemandServingMode{
-				ModelId: common.String(modelId),
-			},
-			ChatRequest: generativeaiinference.CohereChatRequest{
-				Message:     common.String(inputText),
-				MaxTokens:   common.Int(600),
-				Temperature: common.Float64(0.1),
-				TopP:        common.Float64(0.75),
-				TopK:        common.Int(0),
-				IsStream:    common.Bool(false),
-			},
-		},
-	}
+func (c *CohereImplementation) CreateChatRequest(compartmentId, modelId, inputText string, params GenerationParams) generativeaiinference.ChatRequest {
+	return newCohereChatRequest(compartmentId, modelId, inputText, params.ForModel(c))
 }
 
 // CreateChatRequestWithContext cria uma requisição de chat com contexto histórico para modelos Cohere
-func (c *CohereImplementation) CreateChatRequestWithContext(compartmentId, modelId, inputText string, context []Question) generativeaiinference.ChatRequest {
+func (c *CohereImplementation) CreateChatRequestWithContext(compartmentId, modelId, inputText string, context []Question, params GenerationParams) generativeaiinference.ChatRequest {
 	// Para modelos Cohere, incluimos o contexto como parte da mensagem
 	contextMessage := inputText
 
-	// Adicionar contexto histórico limitado (últimas 3 interações para não exceder limites)
-	maxContext := 3
-	startIndex := 0
-	if len(context) > maxContext {
-		startIndex = len(context) - maxContext
-	}
-
-	if len(context) > 0 {
+	// Adicionar contexto histórico limitado (últimas interações e fixadas, para não exceder limites)
+	if selected := SelectContext(context, c.ContextWindow()); len(selected) > 0 {
 		contextMessage = "Contexto da conversa anterior:\n"
-		for i, q := range context[startIndex:] {
-			if q.Success {
-				contextMessage += fmt.Sprintf("\nPergunta %d: %s\nResposta %d: %s\n", i+1, q.Text, i+1, q.Response)
-			}
+		for i, q := range selected {
+			contextMessage += fmt.Sprintf("\nPergunta %d: %s\nResposta %d: %s\n", i+1, q.SentText(), i+1, q.Response)
 		}
 		contextMessage += "\nPergunta atual: " + inputText
 	}
 
-	return generativeaiinference.ChatRequest{
-		ChatDetails: generativeaiinference.ChatDetails{
-			CompartmentId: common.String(compartmentId),
-			ServingMode: generativeaiinference.OnDemandServingMode{
-				ModelId: common.String(modelId),
-			},
-			ChatRequest: generativeaiinference.CohereChatRequest{
-				Message:     common.String(contextMessage),
-				MaxTokens:   common.Int(600),
-				Temperature: common.Float64(0.1),
-				TopP:        common.Float64(0.75),
-				TopK:        common.Int(0),
-				IsStream:    common.Bool(false),
-			},
-		},
-	}
+	return newCohereChatRequest(compartmentId, modelId, contextMessage, params.ForModel(c))
 }
 
 // ProcessResponse processa a resposta específica para modelos Cohere
-func (c *CohereImplementation) ProcessResponse(response generativeaiinference.ChatResponse) (string, error) {
+func (c *CohereImplementation) ProcessResponse(response generativeaiinference.ChatResponse) ([]string, error) {
 	if chatResponse, ok := response.ChatResult.ChatResponse.(generativeaiinference.CohereChatResponse); ok {
 		if chatResponse.Text != nil {
-			return *chatResponse.Text, nil
+			return []string{*chatResponse.Text}, nil
 		}
-		return "", fmt.Errorf("resposta vazia do modelo Cohere")
+		return nil, fmt.Errorf("resposta vazia do modelo Cohere")
 	}
 
-	return "", fmt.Errorf("formato de resposta inesperado para Cohere: %T", response.ChatResult.ChatResponse)
+	return nil, fmt.Errorf("formato de resposta inesperado para Cohere: %T", response.ChatResult.ChatResponse)
+}
+
+// ExtractUsage retorna o consumo de tokens informado pelo modelo Cohere
+func (c *CohereImplementation) ExtractUsage(response generativeaiinference.ChatResponse) (TokenUsage, bool) {
+	chatResponse, ok := response.ChatResult.ChatResponse.(generativeaiinference.CohereChatResponse)
+	if !ok || chatResponse.Usage == nil {
+		return TokenUsage{}, false
+	}
+
+	usage := TokenUsage{}
+	if chatResponse.Usage.PromptTokens != nil {
+		usage.PromptTokens = *chatResponse.Usage.PromptTokens
+	}
+	if chatResponse.Usage.CompletionTokens != nil {
+		usage.CompletionTokens = *chatResponse.Usage.CompletionTokens
+	}
+	if chatResponse.Usage.TotalTokens != nil {
+		usage.TotalTokens = *chatResponse.Usage.TotalTokens
+	}
+	return usage, true
 }
 
 // GetModelFamily retorna a família do modelo
 func (c *CohereImplementation) GetModelFamily() string {
 	return "cohere"
 }
+
+// SupportsMultipleGenerations indica se o modelo gera mais de uma resposta por requisição
+func (c *CohereImplementation) SupportsMultipleGenerations() bool {
+	// A API de chat Cohere não aceita NumGenerations
+	return false
+}
+
+// ContextWindow retorna quantas perguntas anteriores, além das fixadas, vão no contexto
+func (c *CohereImplementation) ContextWindow() int {
+	return CohereContextWindow
+}
+
+// newCohereChatRequest monta a requisição Cohere aplicando os parâmetros de geração
+func newCohereChatRequest(compartmentId, modelId, message string, params GenerationParams) generativeaiinference.ChatRequest {
+	var preamble *string
+	if params.SystemPrompt != "" {
+		preamble = common.String(params.SystemPrompt)
+	}
+
+	return generativeaiinference.ChatRequest{
+		ChatDetails: generativeaiinference.ChatDetails{
+			CompartmentId: common.String(compartmentId),
+			ServingMode: generativeaiinference.OnDemandServingMode{
+				ModelId: common.String(modelId),
+			},
+			ChatRequest: generativeaiinference.CohereChatRequest{
+				Message:          common.String(message),
+				PreambleOverride: preamble,
+				MaxTokens:        common.Int(params.MaxTokens),
+				Temperature:      common.Float64(params.Temperature),
+				TopP:             common.Float64(params.TopP),
+				TopK:             common.Int(params.TopK),
+				StopSequences:    params.StopSequences,
+				FrequencyPenalty: params.FrequencyPenalty,
+				PresencePenalty:  params.PresencePenalty,
+				Seed:             params.Seed,
+				IsStream:         common.Bool(false),
+			},
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Janela de contexto de cada família: quantas perguntas anteriores, além das fixadas, são enviadas
+const (
+	MetaContextWindow   = 5
+	CohereContextWindow = 3
+)
+
+// SelectContext escolhe as perguntas enviadas como contexto, na ordem da conversa: as últimas
+// window perguntas respondidas e não excluídas (/excluir), mais as fixadas (/fixar) que ficaram
+// fora da janela
+func SelectContext(questions []Question, window int) []Question {
+	var eligible []Question
+	for _, q := range questions {
+		if q.Success && !q.Excluded {
+			eligible = append(eligible, q)
+		}
+	}
+
+	start := max(len(eligible)-window, 0)
+	var selected []Question
+	for i, q := range eligible {
+		if i >= start || q.Pinned {
+			selected = append(selected, q)
+		}
+	}
+	return selected
+}
+
+// SetPinned fixa (ou solta) a pergunta do ramo ativo no contexto; fixar desfaz a exclusão
+func (cs *ChatSession) SetPinned(id int, pinned bool) (Question, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	q, err := cs.activeQuestion(id)
+	if err != nil {
+		return Question{}, err
+	}
+	q.Pinned = pinned
+	if pinned {
+		q.Excluded = false
+	}
+	return *q, nil
+}
+
+// SetExcluded deixa (ou volta a colocar) a pergunta do ramo ativo fora do contexto; excluir desfaz a fixação
+func (cs *ChatSession) SetExcluded(id int, excluded bool) (Question, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	q, err := cs.activeQuestion(id)
+	if err != nil {
+		return Question{}, err
+	}
+	q.Excluded = excluded
+	if excluded {
+		q.Pinned = false
+	}
+	return *q, nil
+}
+
+// Forget remove a pergunta do histórico, de qualquer ramo; as perguntas seguintes passam a
+// apontar para a anterior a ela
+func (cs *ChatSession) Forget(id int) (Question, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.forget(id)
+}
+
+// forget é Forget com o lock já obtido
+func (cs *ChatSession) forget(id int) (Question, error) {
+	forgotten, ok := cs.findQuestion(id)
+	if !ok {
+		return Question{}, fmt.Errorf("pergunta não encontrada: %d", id)
+	}
+	cs.linkActive()
+
+	isForgotten := func(q Question) bool { return q.ID == id }
+	cs.questions = slices.DeleteFunc(cs.questions, isForgotten)
+	cs.otherTurns = slices.DeleteFunc(cs.otherTurns, isForgotten)
+	for _, turns := range [][]Question{cs.questions, cs.otherTurns} {
+		for i := range turns {
+			if turns[i].Parent == id {
+				turns[i].Parent = forgotten.Parent
+			}
+		}
+	}
+	return forgotten, nil
+}
+
+// activeQuestion retorna a pergunta do ramo ativo, para alteração (com o lock obtido)
+func (cs *ChatSession) activeQuestion(id int) (*Question, error) {
+	index := slices.IndexFunc(cs.questions, func(q Question) bool { return q.ID == id })
+	if index < 0 {
+		return nil, fmt.Errorf("a pergunta %d não está no ramo ativo", id)
+	}
+	return &cs.questions[index], nil
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Identificação do documento gerado por ExportJSON. A versão muda apenas quando campos
+// existentes são removidos ou mudam de significado; campos novos não alteram a versão.
+const (
+	SessionExportFormat  = "agente.session"
+	SessionExportVersion = 1
+)
+
+// SessionExport é o documento JSON de uma sessão exportada
+type SessionExport struct {
+	Format     string       `json:"format"`
+	Version    int          `json:"version"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Session    *ChatSession `json:"session"`
+}
+
+// ExportJSON exporta a sessão completa, com todos os campos de cada pergunta
+func (cs *ChatSession) ExportJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(SessionExport{
+		Format:     SessionExportFormat,
+		Version:    SessionExportVersion,
+		ExportedAt: time.Now(),
+		Session:    cs,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao exportar sessão: %v", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ExportMarkdown exporta a sessão em Markdown, mantendo a formatação das respostas do modelo
+func (cs *ChatSession) ExportMarkdown() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("# Sessão de chat - %s\n\n", cs.startTime.Format("02/01/2006 15:04")))
+	builder.WriteString(fmt.Sprintf("- **Modelo:** %s (`%s`)\n", cs.modelName, cs.modelID))
+	if cs.id != "" {
+		builder.WriteString(fmt.Sprintf("- **Sessão:** `%s`\n", cs.id))
+	}
+	builder.WriteString(fmt.Sprintf("- **Perguntas:** %d\n", len(cs.questions)))
+
+	for _, q := range cs.questions {
+		builder.WriteString(fmt.Sprintf("\n---\n\n## Pergunta %d\n\n", q.ID))
+		builder.WriteString(fmt.Sprintf("*%s", q.Timestamp.Format("02/01/2006 15:04:05")))
+		if q.Model != "" && q.Model != cs.modelID {
+			builder.WriteString(fmt.Sprintf(" · %s", q.Model))
+		}
+		builder.WriteString("*\n\n")
+
+		for _, line := range strings.Split(strings.TrimRight(q.Text, "\n"), "\n") {
+			builder.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		builder.WriteString("\n")
+
+		for _, a := range q.Attachments {
+			builder.WriteString(fmt.Sprintf("- 📎 %s\n", a.Describe()))
+		}
+		for _, c := range q.Commands {
+			builder.WriteString(fmt.Sprintf("- 🐚 %s\n", c.Describe()))
+		}
+		for _, r := range q.Recalled {
+			builder.WriteString(fmt.Sprintf("- 🔁 %s\n", r))
+		}
+		if q.Source != "" {
+			builder.WriteString(fmt.Sprintf("- 📥 Importada de %s\n", q.Source))
+		}
+		if len(q.Attachments) > 0 || len(q.Commands) > 0 || len(q.Recalled) > 0 || q.Source != "" {
+			builder.WriteString("\n")
+		}
+
+		if !q.Success {
+			builder.WriteString(fmt.Sprintf("**Erro:** %s\n", q.Error))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("### Resposta\n\n%s\n\n", strings.TrimRight(q.Response, "\n")))
+		if q.Source != "" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("*Processado em %v", q.ProcessTime.Round(time.Millisecond)))
+		if q.Params.Seed != nil {
+			builder.WriteString(fmt.Sprintf(" · seed %d", *q.Params.Seed))
+		}
+		if q.Usage.TotalTokens > 0 {
+			builder.WriteString(fmt.Sprintf(" · %d tokens", q.Usage.TotalTokens))
+		}
+		builder.WriteString("*\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Avaliação de uma resposta (/bom e /ruim)
+const (
+	RatingGood = 1
+	RatingBad  = -1
+)
+
+// DatasetExample é um par pergunta/resposta no formato JSONL aceito pelo fine-tuning da OCI
+type DatasetExample struct {
+	Prompt     string `json:"prompt"`
+	Completion string `json:"completion"`
+}
+
+// Redact mascara os dados sensíveis do prompt e da resposta, com os mesmos placeholders nos dois.
+// Retorna o exemplo mascarado e quantas ocorrências foram mascaradas (redactor nil não mascara).
+func (e DatasetExample) Redact(redactor *Redactor) (DatasetExample, int) {
+	if redactor == nil {
+		return e, 0
+	}
+	redaction := redactor.NewRedaction()
+	masked := DatasetExample{Prompt: redaction.Apply(e.Prompt), Completion: redaction.Apply(e.Completion)}
+	return masked, redaction.Count()
+}
+
+// DatasetFilter seleciona as respostas bem avaliadas exportadas por "agente dataset export"
+type DatasetFilter struct {
+	Models []string  // IDs aceitos (vazio: todos)
+	Since  time.Time // Perguntas a partir deste instante (zero: sem limite)
+	Until  time.Time // Perguntas antes deste instante (zero: sem limite)
+	Tags   []string  // A pergunta precisa ter todas as tags
+}
+
+// RateLast avalia a última resposta do ramo ativo; o comentário só é guardado junto com a avaliação
+func (cs *ChatSession) RateLast(rating int, comment string) (Question, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	q, err := cs.lastAnswer()
+	if err != nil {
+		return Question{}, err
+	}
+	q.Rating, q.RatingComment = rating, strings.TrimSpace(comment)
+	return *q, nil
+}
+
+// TagLast acrescenta e remove tags da última resposta do ramo ativo
+func (cs *ChatSession) TagLast(add, remove []string) (Question, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	q, err := cs.lastAnswer()
+	if err != nil {
+		return Question{}, err
+	}
+	// Uma fatia nova: cópias anteriores da pergunta não são alteradas
+	tags := slices.Clone(q.Tags)
+	for _, tag := range add {
+		if tag = NormalizeTag(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	tags = slices.DeleteFunc(tags, func(tag string) bool {
+		return slices.ContainsFunc(remove, func(r string) bool { return NormalizeTag(r) == tag })
+	})
+	q.Tags = tags
+	return *q, nil
+}
+
+// NormalizeTag deixa a tag em minúsculas, sem "#" no início e sem espaços
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(tag), "#")), "-"))
+}
+
+// DatasetExamples retorna as respostas bem avaliadas (/bom), de todos os ramos, que passam pelo filtro.
+// O prompt é o texto enviado ao modelo, com anexos e saídas de comandos, sem o contexto.
+func (cs *ChatSession) DatasetExamples(filter DatasetFilter) []DatasetExample {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var examples []DatasetExample
+	for _, q := range cs.allQuestions() {
+		if !q.Success || q.Rating != RatingGood {
+			continue
+		}
+		if len(filter.Models) > 0 && !slices.Contains(filter.Models, cmp.Or(q.Model, cs.modelID)) {
+			continue
+		}
+		if (!filter.Since.IsZero() && q.Timestamp.Before(filter.Since)) ||
+			(!filter.Until.IsZero() && !q.Timestamp.Before(filter.Until)) {
+			continue
+		}
+		if !slices.ContainsFunc(filter.Tags, func(tag string) bool { return !slices.Contains(q.Tags, NormalizeTag(tag)) }) {
+			examples = append(examples, DatasetExample{Prompt: q.SentText(), Completion: q.Response})
+		}
+	}
+	return examples
+}
+
+// lastAnswer retorna a última pergunta do ramo ativo, que precisa ter resposta (com o lock obtido)
+func (cs *ChatSession) lastAnswer() (*Question, error) {
+	if len(cs.questions) == 0 {
+		return nil, fmt.Errorf("nenhuma resposta para avaliar ainda")
+	}
+	q := &cs.questions[len(cs.questions)-1]
+	if !q.Success {
+		return nil, fmt.Errorf("a pergunta %d não teve resposta", q.ID)
+	}
+	return q, nil
+}
@@ -0,0 +1,279 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Valores padrão dos parâmetros de geração
+const (
+	DefaultMaxTokens      = 600
+	DefaultTemperature    = 0.1
+	DefaultTopP           = 0.75
+	DefaultTopK           = 0
+	DefaultNumGenerations = 1
+	MaxNumGenerations     = 5
+)
+
+// GenerationParams contém os parâmetros de amostragem enviados ao modelo
+type GenerationParams struct {
+	MaxTokens        int      `json:"max_tokens"`
+	Temperature      float64  `json:"temperature"`
+	TopP             float64  `json:"top_p"`
+	TopK             int      `json:"top_k"`
+	StopSequences    []string `json:"stop,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"` // nil = padrão do modelo
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`  // nil = padrão do modelo
+	Seed             *int     `json:"seed,omitempty"`              // nil = geração não determinística
+	NumGenerations   int      `json:"num_generations"`
+	SystemPrompt     string   `json:"system,omitempty"` // Instrução de sistema (preamble no Cohere)
+}
+
+// DefaultGenerationParams retorna os parâmetros usados por padrão nas sessões
+func DefaultGenerationParams() GenerationParams {
+	return GenerationParams{
+		MaxTokens:      DefaultMaxTokens,
+		Temperature:    DefaultTemperature,
+		TopP:           DefaultTopP,
+		TopK:           DefaultTopK,
+		NumGenerations: DefaultNumGenerations,
+	}
+}
+
+// Set altera um parâmetro pelo nome. Os valores "padrao" ou "nenhum" restauram o padrão.
+func (p *GenerationParams) Set(name, value string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	value = strings.TrimSpace(value)
+	reset := isResetValue(value)
+	defaults := DefaultGenerationParams()
+
+	switch name {
+	case "max_tokens", "maxtokens", "tokens":
+		if reset {
+			p.MaxTokens = defaults.MaxTokens
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("max_tokens deve ser um inteiro positivo: %s", value)
+		}
+		p.MaxTokens = n
+	case "temperatura", "temperature", "temp":
+		if reset {
+			p.Temperature = defaults.Temperature
+			return nil
+		}
+		f, err := parseFloatInRange(value, 0, 5)
+		if err != nil {
+			return fmt.Errorf("temperatura inválida: %v", err)
+		}
+		p.Temperature = f
+	case "top_p", "topp":
+		if reset {
+			p.TopP = defaults.TopP
+			return nil
+		}
+		f, err := parseFloatInRange(value, 0, 1)
+		if err != nil {
+			return fmt.Errorf("top_p inválido: %v", err)
+		}
+		p.TopP = f
+	case "top_k", "topk":
+		if reset {
+			p.TopK = defaults.TopK
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("top_k deve ser um inteiro não negativo: %s", value)
+		}
+		p.TopK = n
+	case "stop", "parada":
+		if reset {
+			p.StopSequences = nil
+			return nil
+		}
+		p.StopSequences = parseStopSequences(value)
+	case "frequencia", "frequency", "frequency_penalty":
+		if reset {
+			p.FrequencyPenalty = nil
+			return nil
+		}
+		f, err := parseFloatInRange(value, -2, 2)
+		if err != nil {
+			return fmt.Errorf("penalidade de frequência inválida: %v", err)
+		}
+		p.FrequencyPenalty = &f
+	case "presenca", "presence", "presence_penalty":
+		if reset {
+			p.PresencePenalty = nil
+			return nil
+		}
+		f, err := parseFloatInRange(value, -2, 2)
+		if err != nil {
+			return fmt.Errorf("penalidade de presença inválida: %v", err)
+		}
+		p.PresencePenalty = &f
+	case "seed", "semente":
+		if reset {
+			p.Seed = nil
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("seed deve ser um inteiro: %s", value)
+		}
+		p.Seed = &n
+	case "n", "geracoes", "generations":
+		if reset {
+			p.NumGenerations = defaults.NumGenerations
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxNumGenerations {
+			return fmt.Errorf("número de gerações deve estar entre 1 e %d: %s", MaxNumGenerations, value)
+		}
+		p.NumGenerations = n
+	case "sistema", "system":
+		if reset {
+			p.SystemPrompt = ""
+			return nil
+		}
+		p.SystemPrompt = value
+	default:
+		return fmt.Errorf("parâmetro desconhecido: %s", name)
+	}
+
+	return nil
+}
+
+// ForModel retorna os parâmetros efetivamente enviados ao modelo: sem suporte a múltiplas
+// gerações, n volta a 1
+func (p GenerationParams) ForModel(modelImpl ModelImplementation) GenerationParams {
+	if !modelImpl.SupportsMultipleGenerations() {
+		p.NumGenerations = 1
+	}
+	return p
+}
+
+// Validate verifica os intervalos aceitos por Set, para parâmetros que não passaram por ele
+// (ex.: "params" das linhas do batch)
+func (p GenerationParams) Validate() error {
+	switch {
+	case p.MaxTokens < 1:
+		return fmt.Errorf("max_tokens deve ser um inteiro positivo: %d", p.MaxTokens)
+	case p.Temperature < 0 || p.Temperature > 5:
+		return fmt.Errorf("temperatura %.2f fora do intervalo [0.0, 5.0]", p.Temperature)
+	case p.TopP < 0 || p.TopP > 1:
+		return fmt.Errorf("top_p %.2f fora do intervalo [0.0, 1.0]", p.TopP)
+	case p.TopK < 0:
+		return fmt.Errorf("top_k deve ser um inteiro não negativo: %d", p.TopK)
+	case p.FrequencyPenalty != nil && (*p.FrequencyPenalty < -2 || *p.FrequencyPenalty > 2):
+		return fmt.Errorf("penalidade de frequência %.2f fora do intervalo [-2.0, 2.0]", *p.FrequencyPenalty)
+	case p.PresencePenalty != nil && (*p.PresencePenalty < -2 || *p.PresencePenalty > 2):
+		return fmt.Errorf("penalidade de presença %.2f fora do intervalo [-2.0, 2.0]", *p.PresencePenalty)
+	case p.NumGenerations < 1 || p.NumGenerations > MaxNumGenerations:
+		return fmt.Errorf("número de gerações deve estar entre 1 e %d: %d", MaxNumGenerations, p.NumGenerations)
+	}
+	return nil
+}
+
+// Describe retorna uma descrição legível dos parâmetros atuais
+func (p GenerationParams) Describe() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("  • max_tokens: %d\n", p.MaxTokens))
+	builder.WriteString(fmt.Sprintf("  • temperatura: %.2f\n", p.Temperature))
+	builder.WriteString(fmt.Sprintf("  • top_p: %.2f\n", p.TopP))
+	builder.WriteString(fmt.Sprintf("  • top_k: %d\n", p.TopK))
+	builder.WriteString(fmt.Sprintf("  • stop: %s\n", describeStopSequences(p.StopSequences)))
+	builder.WriteString(fmt.Sprintf("  • frequencia: %s\n", describeOptionalFloat(p.FrequencyPenalty)))
+	builder.WriteString(fmt.Sprintf("  • presenca: %s\n", describeOptionalFloat(p.PresencePenalty)))
+	builder.WriteString(fmt.Sprintf("  • seed: %s\n", describeOptionalInt(p.Seed)))
+	builder.WriteString(fmt.Sprintf("  • n (gerações): %d", p.NumGenerations))
+	if p.SystemPrompt != "" {
+		builder.WriteString(fmt.Sprintf("\n  • sistema: %s", p.SystemPrompt))
+	}
+
+	return builder.String()
+}
+
+// Clone retorna uma cópia independente dos parâmetros
+func (p GenerationParams) Clone() GenerationParams {
+	clone := p
+	if p.StopSequences != nil {
+		clone.StopSequences = append([]string(nil), p.StopSequences...)
+	}
+	if p.FrequencyPenalty != nil {
+		v := *p.FrequencyPenalty
+		clone.FrequencyPenalty = &v
+	}
+	if p.PresencePenalty != nil {
+		v := *p.PresencePenalty
+		clone.PresencePenalty = &v
+	}
+	if p.Seed != nil {
+		v := *p.Seed
+		clone.Seed = &v
+	}
+	return clone
+}
+
+// isResetValue verifica se o valor pede a restauração do padrão
+func isResetValue(value string) bool {
+	switch strings.ToLower(value) {
+	case "padrao", "padrão", "default", "nenhum", "none", "off":
+		return true
+	}
+	return false
+}
+
+// parseFloatInRange converte um valor e valida o intervalo permitido
+func parseFloatInRange(value string, min, max float64) (float64, error) {
+	f, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor não numérico: %s", value)
+	}
+	if f < min || f > max {
+		return 0, fmt.Errorf("valor %.2f fora do intervalo [%.1f, %.1f]", f, min, max)
+	}
+	return f, nil
+}
+
+// parseStopSequences separa as sequências de parada por "|"
+func parseStopSequences(value string) []string {
+	var sequences []string
+	for _, s := range strings.Split(value, "|") {
+		s = strings.ReplaceAll(s, `\n`, "\n")
+		if s != "" {
+			sequences = append(sequences, s)
+		}
+	}
+	return sequences
+}
+
+func describeStopSequences(sequences []string) string {
+	if len(sequences) == 0 {
+		return "nenhuma"
+	}
+	quoted := make([]string, len(sequences))
+	for i, s := range sequences {
+		quoted[i] = strconv.Quote(s)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func describeOptionalFloat(v *float64) string {
+	if v == nil {
+		return "padrão do modelo"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+func describeOptionalInt(v *int) string {
+	if v == nil {
+		return "aleatória"
+	}
+	return strconv.Itoa(*v)
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MaxTranscriptBytes limita o tamanho do arquivo de transcrição importado
+const MaxTranscriptBytes int64 = 10 * 1024 * 1024
+
+// TranscriptMessage é uma mensagem no formato de chat da OpenAI: {"role": "user", "content": "..."}.
+// O conteúdo pode ser texto ou uma lista de partes [{"type": "text", "text": "..."}].
+type TranscriptMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// Transcript é uma conversa importada, já convertida em perguntas e respostas
+type Transcript struct {
+	System    string     // Mensagens de sistema, unidas
+	Questions []Question // Pares de mensagens do usuário e do assistente
+	Pending   string     // Última mensagem do usuário, que ficou sem resposta
+	Skipped   int        // Mensagens ignoradas (ferramentas, respostas sem pergunta, sem texto)
+}
+
+// LoadTranscript lê um arquivo de transcrição (lista de mensagens ou objeto com "messages")
+func LoadTranscript(path string) (Transcript, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("erro ao ler transcrição %s: %v", path, err)
+	}
+	if info.Size() > MaxTranscriptBytes {
+		return Transcript{}, fmt.Errorf("transcrição %s excede o limite de %d MB", path, MaxTranscriptBytes/(1024*1024))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("erro ao ler transcrição %s: %v", path, err)
+	}
+	return ParseTranscript(data, path)
+}
+
+// ParseTranscript converte as mensagens em perguntas: cada mensagem do usuário seguida da resposta
+// do assistente vira uma Question. Mensagens consecutivas do mesmo papel são unidas.
+func ParseTranscript(data []byte, source string) (Transcript, error) {
+	var messages []TranscriptMessage
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var wrapper struct {
+			Messages []TranscriptMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return Transcript{}, fmt.Errorf("transcrição inválida em %s: %v", source, err)
+		}
+		messages = wrapper.Messages
+	} else if err := json.Unmarshal(data, &messages); err != nil {
+		return Transcript{}, fmt.Errorf("transcrição inválida em %s: %v", source, err)
+	}
+	if len(messages) == 0 {
+		return Transcript{}, fmt.Errorf("a transcrição %s não tem mensagens", source)
+	}
+
+	var transcript Transcript
+	var system []string
+	var pending []string
+	lastRole := ""
+
+	for i, message := range messages {
+		text, err := messageText(message.Content)
+		if err != nil {
+			return Transcript{}, fmt.Errorf("mensagem %d de %s: %v", i+1, source, err)
+		}
+		text = strings.TrimSpace(text)
+		role := strings.ToLower(message.Role)
+		if text == "" {
+			transcript.Skipped++
+			continue
+		}
+
+		switch role {
+		case "system", "developer":
+			system = append(system, text)
+
+		case "user":
+			pending = append(pending, text)
+
+		case "assistant":
+			switch {
+			case len(pending) > 0:
+				transcript.Questions = append(transcript.Questions, Question{
+					Text:     strings.Join(pending, "\n\n"),
+					Response: text,
+					Success:  true,
+					Source:   source,
+				})
+				pending = nil
+			case lastRole == "assistant":
+				last := &transcript.Questions[len(transcript.Questions)-1]
+				last.Response += "\n\n" + text
+			default:
+				transcript.Skipped++
+				continue
+			}
+
+		default:
+			transcript.Skipped++
+			continue
+		}
+		lastRole = role
+	}
+
+	transcript.System = strings.Join(system, "\n\n")
+	transcript.Pending = strings.Join(pending, "\n\n")
+	if len(transcript.Questions) == 0 && transcript.Pending == "" {
+		return Transcript{}, fmt.Errorf("nenhuma mensagem do usuário encontrada em %s", source)
+	}
+	return transcript, nil
+}
+
+// messageText extrai o texto do conteúdo, seja ele uma string ou uma lista de partes
+func messageText(content json.RawMessage) (string, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", fmt.Errorf("conteúdo deve ser texto ou lista de partes")
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Text != "" && (part.Type == "" || strings.HasSuffix(part.Type, "text")) {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// ImportTranscript acrescenta as perguntas importadas ao histórico, onde passam a fazer parte do
+// contexto. A instrução de sistema só é usada se a sessão ainda não tiver uma; retorna se foi usada.
+func (cs *ChatSession) ImportTranscript(transcript Transcript) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, q := range transcript.Questions {
+		cs.recordQuestion(q)
+	}
+	if transcript.System == "" || cs.params.SystemPrompt != "" {
+		return false
+	}
+	cs.params.SystemPrompt = transcript.System
+	return true
+}
@@ -11,63 +11,43 @@ import (
 type MetaImplementation struct{}
 
 // CreateChatRequest cria uma requisição de chat específica para modelos Meta Llama
-func (m *MetaImplementation) CreateChatRequest(compartmentId, modelId, inputText string) generativeaiinference.ChatRequest {
-	return generativeaiinference.ChatRequest{
-		ChatDetails: generativeaiinference.ChatDetails{
-			CompartmentId: common.String(compartmentId),
-			ServingMode: generativeaiinference.OnDemandServingMode{
-				ModelId: common.String(modelId),
-			},
-			ChatRequest: generativeaiinference.GenericChatRequest{
-				Messages: []generativeaiinference.Message{
-					generativeaiinference.UserMessage{
-						Content: []generativeaiinference.ChatContent{
-							generativeaiinference.TextContent{
-								Text: common.String(inputText),
-							},
-						},
-					},
+func (m *MetaImplementation) CreateChatRequest(compartmentId, modelId, inputText string, params GenerationParams) generativeaiinference.ChatRequest {
+	messages := []generativeaiinference.Message{
+		generativeaiinference.UserMessage{
+			Content: []generativeaiinference.ChatContent{
+				generativeaiinference.TextContent{
+					Text: common.String(inputText),
 				},
-				MaxTokens:   common.Int(600),
-				Temperature: common.Float64(0.1),
-				TopP:        common.Float64(0.75),
-				IsStream:    common.Bool(false),
 			},
 		},
 	}
+
+	return newGenericChatRequest(compartmentId, modelId, messages, params.ForModel(m))
 }
 
 // CreateChatRequestWithContext cria uma requisição de chat com contexto histórico para modelos Meta Llama
-func (m *MetaImplementation) CreateChatRequestWithContext(compartmentId, modelId, inputText string, context []Question) generativeaiinference.ChatRequest {
+func (m *MetaImplementation) CreateChatRequestWithContext(compartmentId, modelId, inputText string, context []Question, params GenerationParams) generativeaiinference.ChatRequest {
 	var messages []generativeaiinference.Message
 
-	// Adicionar contexto histórico limitado (últimas 5 interações para não exceder limites de token)
-	maxContext := 5
-	startIndex := 0
-	if len(context) > maxContext {
-		startIndex = len(context) - maxContext
-	}
-
-	for _, q := range context[startIndex:] {
-		if q.Success {
-			// Adicionar pergunta do usuário
-			messages = append(messages, generativeaiinference.UserMessage{
-				Content: []generativeaiinference.ChatContent{
-					generativeaiinference.TextContent{
-						Text: common.String(q.Text),
-					},
+	// Adicionar contexto histórico limitado (últimas interações e fixadas, para não exceder limites de token)
+	for _, q := range SelectContext(context, m.ContextWindow()) {
+		// Adicionar pergunta do usuário
+		messages = append(messages, generativeaiinference.UserMessage{
+			Content: []generativeaiinference.ChatContent{
+				generativeaiinference.TextContent{
+					Text: common.String(q.SentText()),
 				},
-			})
-
-			// Adicionar resposta do assistente
-			messages = append(messages, generativeaiinference.AssistantMessage{
-				Content: []generativeaiinference.ChatContent{
-					generativeaiinference.TextContent{
-						Text: common.String(q.Response),
-					},
+			},
+		})
+
+		// Adicionar resposta do assistente
+		messages = append(messages, generativeaiinference.AssistantMessage{
+			Content: []generativeaiinference.ChatContent{
+				generativeaiinference.TextContent{
+					Text: common.String(q.Response),
 				},
-			})
-		}
+			},
+		})
 	}
 
 	// Adicionar a pergunta atual
@@ -79,44 +59,95 @@ func (m *MetaImplementation) CreateChatRequestWithContext(compartmentId, modelId
 		},
 	})
 
-	return generativeaiinference.ChatRequest{
-		ChatDetails: generativeaiinference.ChatDetails{
-			CompartmentId: common.String(compartmentId),
-			ServingMode: generativeaiinference.OnDemandServingMode{
-				ModelId: common.String(modelId),
-			},
-			ChatRequest: generativeaiinference.GenericChatRequest{
-				Messages:    messages,
-				MaxTokens:   common.Int(600),
-				Temperature: common.Float64(0.1),
-				TopP:        common.Float64(0.75),
-				IsStream:    common.Bool(false),
-			},
-		},
-	}
+	return newGenericChatRequest(compartmentId, modelId, messages, params.ForModel(m))
 }
 
 // ProcessResponse processa a resposta específica para modelos Meta Llama
-func (m *MetaImplementation) ProcessResponse(response generativeaiinference.ChatResponse) (string, error) {
+func (m *MetaImplementation) ProcessResponse(response generativeaiinference.ChatResponse) ([]string, error) {
 	if chatResponse, ok := response.ChatResult.ChatResponse.(generativeaiinference.GenericChatResponse); ok {
-		if len(chatResponse.Choices) > 0 {
-			content := chatResponse.Choices[0].Message.GetContent()
+		var texts []string
+		for _, choice := range chatResponse.Choices {
+			if choice.Message == nil {
+				continue
+			}
+			content := choice.Message.GetContent()
 			if len(content) > 0 {
-				// Extrair o texto do primeiro conteúdo
+				// Extrair o texto do primeiro conteúdo de cada geração
 				if textContent, ok := content[0].(generativeaiinference.TextContent); ok {
 					if textContent.Text != nil {
-						return *textContent.Text, nil
+						texts = append(texts, *textContent.Text)
 					}
 				}
 			}
 		}
-		return "", fmt.Errorf("nenhuma resposta recebida do modelo Meta Llama")
+		if len(texts) > 0 {
+			return texts, nil
+		}
+		return nil, fmt.Errorf("nenhuma resposta recebida do modelo Meta Llama")
 	}
 
-	return "", fmt.Errorf("formato de resposta inesperado para Meta Llama: %T", response.ChatResult.ChatResponse)
+	return nil, fmt.Errorf("formato de resposta inesperado para Meta Llama: %T", response.ChatResult.ChatResponse)
+}
+
+// ExtractUsage retorna o consumo de tokens; a resposta genérica não informa uso
+func (m *MetaImplementation) ExtractUsage(response generativeaiinference.ChatResponse) (TokenUsage, bool) {
+	return TokenUsage{}, false
 }
 
 // GetModelFamily retorna a família do modelo
 func (m *MetaImplementation) GetModelFamily() string {
 	return "meta"
 }
+
+// SupportsMultipleGenerations indica se o modelo gera mais de uma resposta por requisição
+func (m *MetaImplementation) SupportsMultipleGenerations() bool {
+	return true
+}
+
+// ContextWindow retorna quantas perguntas anteriores, além das fixadas, vão no contexto
+func (m *MetaImplementation) ContextWindow() int {
+	return MetaContextWindow
+}
+
+// newGenericChatRequest monta a requisição genérica aplicando os parâmetros de geração
+func newGenericChatRequest(compartmentId, modelId string, messages []generativeaiinference.Message, params GenerationParams) generativeaiinference.ChatRequest {
+	// A instrução de sistema, quando definida, é sempre a primeira mensagem
+	if params.SystemPrompt != "" {
+		system := generativeaiinference.SystemMessage{
+			Content: []generativeaiinference.ChatContent{
+				generativeaiinference.TextContent{
+					Text: common.String(params.SystemPrompt),
+				},
+			},
+		}
+		messages = append([]generativeaiinference.Message{system}, messages...)
+	}
+
+	// top_k 0 deixa o padrão do modelo
+	var topK *int
+	if params.TopK > 0 {
+		topK = common.Int(params.TopK)
+	}
+
+	return generativeaiinference.ChatRequest{
+		ChatDetails: generativeaiinference.ChatDetails{
+			CompartmentId: common.String(compartmentId),
+			ServingMode: generativeaiinference.OnDemandServingMode{
+				ModelId: common.String(modelId),
+			},
+			ChatRequest: generativeaiinference.GenericChatRequest{
+				Messages:         messages,
+				MaxTokens:        common.Int(params.MaxTokens),
+				Temperature:      common.Float64(params.Temperature),
+				TopP:             common.Float64(params.TopP),
+				TopK:             topK,
+				Stop:             params.StopSequences,
+				FrequencyPenalty: params.FrequencyPenalty,
+				PresencePenalty:  params.PresencePenalty,
+				Seed:             params.Seed,
+				NumGenerations:   common.Int(params.NumGenerations),
+				IsStream:         common.Bool(false),
+			},
+		},
+	}
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"agente/internal/fuzzy"
+)
+
+// Pontuação de cada palavra da busca ao comparar com as palavras do modelo
+const (
+	matchExact  = 3 // Palavra igual: "8b" em "Llama 3.1 8B"
+	matchPrefix = 2 // Início de palavra: "comm" em "command"
+	matchFuzzy  = 1 // Erro de digitação: "lama" em "llama"
+
+	// Bônus quando a busca é o nome inteiro do modelo: "command-r" é o Command R, não o Command
+	// R Plus, que também contém as duas palavras
+	matchFullName = 1
+)
+
+// FindModels retorna os modelos que melhor correspondem à busca, na ordem de exibição.
+// Aceita o ID completo ou partes do ID e do nome (ex.: "llama 8b", "command-r+"), tolerando
+// pequenos erros de digitação. Mais de um resultado indica que a busca é ambígua.
+func FindModels(query string) []string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	for _, id := range ModelOrder {
+		if query == id {
+			return []string{id}
+		}
+	}
+
+	scores := make(map[string]int)
+	best := 0
+	words := queryWords(query)
+	for _, id := range ModelOrder {
+		score := matchScore(words, modelWords(id+" "+SupportedModels[id]))
+		if score > 0 && isFullName(words, id) {
+			score += matchFullName
+		}
+		scores[id] = score
+		best = max(best, score)
+	}
+	if best == 0 {
+		return nil
+	}
+
+	var ids []string
+	for _, id := range ModelOrder {
+		if scores[id] == best {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// MatchModel resolve o modelo de --model, AGENTE_MODEL ou de um comando pela mesma busca do menu.
+// Sem escolha interativa, uma busca que corresponde a mais de um modelo é um erro com os candidatos.
+func MatchModel(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	for _, id := range ModelOrder {
+		if strings.EqualFold(query, id) {
+			return id, nil
+		}
+	}
+
+	matches := FindModels(query)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("modelo não suportado: %s. Use 'agente models' para ver os modelos", query)
+	case 1:
+		return matches[0], nil
+	}
+	candidates := make([]string, len(matches))
+	for i, id := range matches {
+		candidates[i] = fmt.Sprintf("%s (%s)", id, SupportedModels[id])
+	}
+	return "", fmt.Errorf("mais de um modelo corresponde a %q: %s. Detalhe a busca ou use o ID",
+		query, strings.Join(candidates, ", "))
+}
+
+// matchScore soma a pontuação de cada palavra da busca; 0 se alguma não corresponde ao modelo
+func matchScore(query, words []string) int {
+	total := 0
+	for _, q := range query {
+		best := 0
+		for _, w := range words {
+			best = max(best, wordScore(q, w))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+func wordScore(query, word string) int {
+	switch {
+	case query == word:
+		return matchExact
+	case strings.HasPrefix(word, query):
+		return matchPrefix
+	}
+
+	// Palavras curtas ("r", "8b") precisam ser exatas; as longas toleram erros de digitação
+	length := len([]rune(query))
+	if length < 4 {
+		return 0
+	}
+	allowed := 1
+	if length >= 6 {
+		allowed = 2
+	}
+	if fuzzy.Distance(query, word) <= allowed {
+		return matchFuzzy
+	}
+	// Também como início de palavra: "comand" em "command-r"
+	if runes := []rune(word); len(runes) > length && fuzzy.Distance(query, string(runes[:length])) <= allowed {
+		return matchFuzzy
+	}
+	return 0
+}
+
+// isFullName indica se as palavras da busca formam exatamente o nome do modelo: o ID sem o
+// fornecedor e a data ("command-r" em "cohere.command-r-08-2024") ou o nome exibido, com ou sem o
+// fornecedor ("Command R" em "Cohere Command R (Agosto 2024)")
+func isFullName(query []string, id string) bool {
+	_, name, _ := strings.Cut(id, ".")
+	idWords := queryWords(name)
+	for len(idWords) > 0 && strings.IndexFunc(idWords[len(idWords)-1], unicode.IsLetter) < 0 {
+		idWords = idWords[:len(idWords)-1]
+	}
+
+	display, _, _ := strings.Cut(SupportedModels[id], "(")
+	displayWords := queryWords(display)
+	names := [][]string{idWords, displayWords}
+	if len(displayWords) > 1 {
+		names = append(names, displayWords[1:])
+	}
+	for _, words := range names {
+		if slices.Equal(query, words) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryWords separa a busca em palavras; "+" vira "plus" ("command-r+" → command, r, plus)
+func queryWords(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "+", " plus ")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+}
+
+// modelWords separa o ID e o nome do modelo em palavras. Versões como "3.3" ficam inteiras e
+// prefixos como "meta.llama" também entram separados.
+func modelWords(text string) []string {
+	var words []string
+	for _, word := range queryWords(text) {
+		word = strings.Trim(word, ".")
+		if word == "" {
+			continue
+		}
+		words = append(words, word)
+		if strings.Contains(word, ".") && strings.IndexFunc(word, unicode.IsLetter) >= 0 {
+			words = append(words, strings.Split(word, ".")...)
+		}
+	}
+	return words
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindModels(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"cohere.command-r-08-2024", []string{ModelCohereCommandR08}},
+		{"command-r", []string{ModelCohereCommandR08}},
+		{"Command R", []string{ModelCohereCommandR08}},
+		{"cohere command r", []string{ModelCohereCommandR08}},
+		{"command-r+", []string{ModelCohereCommandRPlus08}},
+		{"command r plus", []string{ModelCohereCommandRPlus08}},
+		{"command", []string{ModelCohereCommandA03, ModelCohereCommandR08, ModelCohereCommandRPlus08}},
+		{"llama 8b", []string{ModelMetaLlama31_8B}},
+		{"lama 3.3", []string{ModelMetaLlama33_70B}},
+		{"comand-a", []string{ModelCohereCommandA03}},
+		{"gpt", nil},
+	}
+	for _, tt := range tests {
+		if got := FindModels(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("FindModels(%q) = %q, esperado %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchModelCommandR(t *testing.T) {
+	id, err := MatchModel("command-r")
+	if err != nil || id != ModelCohereCommandR08 {
+		t.Fatalf("MatchModel(\"command-r\") = %q, %v, esperado %s", id, err, ModelCohereCommandR08)
+	}
+	if _, err := MatchModel("llama 70b"); err == nil {
+		t.Errorf("MatchModel(\"llama 70b\") sem erro, esperado ambiguidade")
+	}
+}
@@ -29,12 +29,36 @@ var SupportedModels = map[string]string{
 	ModelMetaLlama2_70B:       "Meta Llama 2 70B Chat",
 }
 
+// Ordem de exibição dos modelos suportados
+var ModelOrder = []string{
+	ModelCohereCommandA03,
+	ModelCohereCommandR08,
+	ModelCohereCommandRPlus08,
+	ModelMetaLlama33_70B,
+	ModelMetaLlama31_70B,
+	ModelMetaLlama31_8B,
+	ModelMetaLlama2_70B,
+}
+
 // Interface para implementações de modelos
 type ModelImplementation interface {
-	CreateChatRequest(compartmentId, modelId, inputText string) generativeaiinference.ChatRequest
-	CreateChatRequestWithContext(compartmentId, modelId, inputText string, context []Question) generativeaiinference.ChatRequest
-	ProcessResponse(response generativeaiinference.ChatResponse) (string, error)
+	CreateChatRequest(compartmentId, modelId, inputText string, params GenerationParams) generativeaiinference.ChatRequest
+	CreateChatRequestWithContext(compartmentId, modelId, inputText string, context []Question, params GenerationParams) generativeaiinference.ChatRequest
+	// ProcessResponse retorna os textos gerados (mais de um quando NumGenerations > 1)
+	ProcessResponse(response generativeaiinference.ChatResponse) ([]string, error)
+	// ExtractUsage retorna o consumo de tokens, quando a família informa
+	ExtractUsage(response generativeaiinference.ChatResponse) (TokenUsage, bool)
 	GetModelFamily() string
+	SupportsMultipleGenerations() bool
+	// ContextWindow retorna quantas perguntas anteriores, além das fixadas, vão no contexto
+	ContextWindow() int
+}
+
+// TokenUsage contém o consumo de tokens de uma requisição
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // Função para determinar a família do modelo
@@ -0,0 +1,334 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Nomes dos detectores embutidos
+const (
+	DetectorPEM   = "pem"
+	DetectorOCID  = "ocid"
+	DetectorEmail = "email"
+	DetectorCNPJ  = "cnpj"
+	DetectorCPF   = "cpf"
+	DetectorToken = "token"
+)
+
+// BuiltinDetectors lista os detectores embutidos na ordem em que são aplicados
+var BuiltinDetectors = []string{
+	DetectorPEM,
+	DetectorOCID,
+	DetectorEmail,
+	DetectorCNPJ,
+	DetectorCPF,
+	DetectorToken,
+}
+
+// Detector identifica um tipo de dado sensível no texto.
+// Se o padrão tiver um grupo nomeado "valor", apenas esse trecho é mascarado.
+// Validate, quando definido, confirma o achado (ex.: dígitos verificadores).
+type Detector struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	Validate func(match string) bool
+}
+
+// Redactor aplica detectores para mascarar dados sensíveis antes do envio ao modelo
+type Redactor struct {
+	detectors      []Detector
+	RestoreAnswers bool // Restaura os valores originais na resposta exibida
+}
+
+// NewRedactor cria um redactor com os detectores embutidos escolhidos e padrões customizados (nome → regex)
+func NewRedactor(enabled []string, custom map[string]string) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, name := range enabled {
+		detector, ok := builtinDetector(strings.ToLower(strings.TrimSpace(name)))
+		if !ok {
+			return nil, fmt.Errorf("detector desconhecido: %s", name)
+		}
+		r.detectors = append(r.detectors, detector)
+	}
+
+	// Ordenar os customizados para que a aplicação seja determinística
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pattern, err := regexp.Compile(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("padrão inválido para o detector %s: %v", name, err)
+		}
+		r.detectors = append(r.detectors, Detector{Name: strings.ToLower(name), Pattern: pattern})
+	}
+
+	return r, nil
+}
+
+// Detectors retorna os nomes dos detectores ativos
+func (r *Redactor) Detectors() []string {
+	names := make([]string, len(r.detectors))
+	for i, d := range r.detectors {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// NewRedaction inicia um mascaramento; placeholders são consistentes dentro da mesma instância
+func (r *Redactor) NewRedaction() *Redaction {
+	return &Redaction{
+		redactor:     r,
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counters:     make(map[string]int),
+		Events:       make(map[string]int),
+	}
+}
+
+// Redaction guarda os valores mascarados de uma requisição para permitir a restauração
+type Redaction struct {
+	redactor     *Redactor
+	placeholders map[string]string // original → placeholder
+	originals    map[string]string // placeholder → original
+	counters     map[string]int
+	Events       map[string]int // detector → quantidade de ocorrências mascaradas
+}
+
+// Apply substitui os dados sensíveis do texto por placeholders como [CPF_1]
+func (rd *Redaction) Apply(text string) string {
+	return rd.apply(text, true)
+}
+
+// apply mascara o texto; count indica se as ocorrências entram em Events
+func (rd *Redaction) apply(text string, count bool) string {
+	if rd == nil || rd.redactor == nil {
+		return text
+	}
+	for _, detector := range rd.redactor.detectors {
+		text = rd.applyDetector(detector, text, count)
+	}
+	return text
+}
+
+// Restore devolve os valores originais no lugar dos placeholders
+func (rd *Redaction) Restore(text string) string {
+	if rd == nil || len(rd.originals) == 0 {
+		return text
+	}
+
+	pairs := make([]string, 0, len(rd.originals)*2)
+	for placeholder, original := range rd.originals {
+		pairs = append(pairs, placeholder, original)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// ApplyToQuestions retorna cópias das perguntas com texto, prompt e resposta mascarados, para uso
+// como contexto. As ocorrências não entram em Events: já foram contadas quando a pergunta foi enviada.
+func (rd *Redaction) ApplyToQuestions(questions []Question) []Question {
+	redacted := make([]Question, len(questions))
+	for i, q := range questions {
+		redacted[i] = q
+		redacted[i].Text = rd.apply(q.Text, false)
+		redacted[i].Prompt = rd.apply(q.Prompt, false)
+		redacted[i].Response = rd.apply(q.Response, false)
+	}
+	return redacted
+}
+
+// ApplyToParams retorna uma cópia dos parâmetros com a instrução de sistema mascarada
+func (rd *Redaction) ApplyToParams(params GenerationParams) GenerationParams {
+	params = params.Clone()
+	params.SystemPrompt = rd.Apply(params.SystemPrompt)
+	return params
+}
+
+// Count retorna o total de ocorrências mascaradas
+func (rd *Redaction) Count() int {
+	if rd == nil {
+		return 0
+	}
+	total := 0
+	for _, n := range rd.Events {
+		total += n
+	}
+	return total
+}
+
+func (rd *Redaction) applyDetector(detector Detector, text string, count bool) string {
+	matches := detector.Pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	// Trechos já mascarados por um detector anterior (ex.: "token=[EMAIL_1]") não são mascarados
+	// de novo, senão a restauração devolveria o placeholder em vez do valor original
+	masked := rd.placeholderSpans(text)
+
+	var builder strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := valueBounds(detector.Pattern, m)
+		if start < 0 || overlaps(masked, start, end) {
+			continue
+		}
+
+		value := text[start:end]
+		if detector.Validate != nil && !detector.Validate(value) {
+			continue
+		}
+
+		builder.WriteString(text[last:start])
+		builder.WriteString(rd.placeholderFor(detector.Name, value))
+		last = end
+		if count {
+			rd.Events[detector.Name]++
+		}
+	}
+	builder.WriteString(text[last:])
+
+	return builder.String()
+}
+
+// placeholderPattern encontra candidatos a placeholder, como [CPF_1]
+var placeholderPattern = regexp.MustCompile(`\[[^\[\]\s]+_\d+\]`)
+
+// placeholderSpans retorna as posições dos placeholders desta instância presentes no texto
+func (rd *Redaction) placeholderSpans(text string) [][]int {
+	if len(rd.originals) == 0 {
+		return nil
+	}
+	var spans [][]int
+	for _, span := range placeholderPattern.FindAllStringIndex(text, -1) {
+		if _, ok := rd.originals[text[span[0]:span[1]]]; ok {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// overlaps indica se o trecho [start, end) cruza algum dos intervalos
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// valueBounds retorna o trecho a mascarar: o primeiro grupo "valor" que casou ou a ocorrência inteira
+func valueBounds(pattern *regexp.Regexp, m []int) (int, int) {
+	hasValueGroup := false
+	for i, name := range pattern.SubexpNames() {
+		if name != "valor" {
+			continue
+		}
+		hasValueGroup = true
+		if m[2*i] >= 0 {
+			return m[2*i], m[2*i+1]
+		}
+	}
+	if hasValueGroup {
+		return -1, -1
+	}
+	return m[0], m[1]
+}
+
+func (rd *Redaction) placeholderFor(detectorName, value string) string {
+	if placeholder, ok := rd.placeholders[value]; ok {
+		return placeholder
+	}
+
+	rd.counters[detectorName]++
+	placeholder := fmt.Sprintf("[%s_%d]", strings.ToUpper(detectorName), rd.counters[detectorName])
+	rd.placeholders[value] = placeholder
+	rd.originals[placeholder] = value
+	return placeholder
+}
+
+// builtinDetector retorna a definição de um detector embutido
+func builtinDetector(name string) (Detector, bool) {
+	switch name {
+	case DetectorPEM:
+		return Detector{Name: name, Pattern: regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]+-----.*?-----END [A-Z0-9 ]+-----`)}, true
+	case DetectorOCID:
+		return Detector{Name: name, Pattern: regexp.MustCompile(`\bocid1\.[a-z0-9_-]+\.[a-z0-9_-]+\.[a-z0-9_.-]*[a-z0-9]`)}, true
+	case DetectorEmail:
+		return Detector{Name: name, Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)}, true
+	case DetectorCNPJ:
+		return Detector{Name: name, Pattern: regexp.MustCompile(`\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`), Validate: IsValidCNPJ}, true
+	case DetectorCPF:
+		return Detector{Name: name, Pattern: regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`), Validate: IsValidCPF}, true
+	case DetectorToken:
+		return Detector{Name: name, Pattern: regexp.MustCompile(
+			`(?i)(?:bearer\s+(?P<valor>[A-Za-z0-9._~+/=-]{16,}))` +
+				`|(?:\b(?:password|passwd|senha|secret|token|api[_-]?key|access[_-]?key)\b\s*[:=]\s*["']?(?P<valor>[^\s"']{6,}))` +
+				`|(?P<valor>\bAKIA[0-9A-Z]{16}\b)` +
+				`|(?P<valor>\bgh[pousr]_[A-Za-z0-9]{36,}\b)` +
+				`|(?P<valor>\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+)`,
+		)}, true
+	}
+	return Detector{}, false
+}
+
+// IsValidCPF valida os dígitos verificadores de um CPF (com ou sem pontuação)
+func IsValidCPF(value string) bool {
+	digits := onlyDigits(value)
+	if len(digits) != 11 || allSameDigit(digits) {
+		return false
+	}
+
+	return checkDigit(digits[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[9] &&
+		checkDigit(digits[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[10]
+}
+
+// IsValidCNPJ valida os dígitos verificadores de um CNPJ (com ou sem pontuação)
+func IsValidCNPJ(value string) bool {
+	digits := onlyDigits(value)
+	if len(digits) != 14 || allSameDigit(digits) {
+		return false
+	}
+
+	return checkDigit(digits[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[12] &&
+		checkDigit(digits[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[13]
+}
+
+// checkDigit calcula um dígito verificador módulo 11
+func checkDigit(digits []int, weights []int) int {
+	sum := 0
+	for i, d := range digits {
+		sum += d * weights[i]
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return 0
+	}
+	return 11 - rest
+}
+
+func onlyDigits(value string) []int {
+	digits := make([]int, 0, len(value))
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	return digits
+}
+
+func allSameDigit(digits []int) bool {
+	for _, d := range digits[1:] {
+		if d != digits[0] {
+			return false
+		}
+	}
+	return true
+}
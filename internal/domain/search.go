@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchResult é uma pergunta salva que corresponde à busca
+type SearchResult struct {
+	SessionID  string    `json:"session_id"`
+	Model      string    `json:"model"`
+	QuestionID int       `json:"question_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Question   string    `json:"question"`
+	Response   string    `json:"response"`
+	Score      int       `json:"score"`
+}
+
+// Reference identifica a origem do resultado, ex.: "sessão 20250314-153012-a1b2, pergunta 3"
+func (r SearchResult) Reference() string {
+	return fmt.Sprintf("sessão %s, pergunta %d", r.SessionID, r.QuestionID)
+}
+
+// SearchTerms normaliza os termos da busca; um termo com espaços (entre aspas) é buscado como frase
+func SearchTerms(args []string) []string {
+	var terms []string
+	for _, arg := range args {
+		term := foldText(oneLine(arg))
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// SearchSession retorna as perguntas respondidas, de todos os ramos, em que todos os termos aparecem no início de
+// uma palavra da pergunta ou da resposta, sem diferenciar maiúsculas e acentos.
+// Ocorrências na pergunta valem o dobro.
+func SearchSession(session *ChatSession, terms []string) []SearchResult {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []SearchResult
+	for _, q := range session.AllQuestions() {
+		if !q.Success {
+			continue
+		}
+		if score := SearchScore(q.Text, q.Response, terms); score > 0 {
+			results = append(results, NewSearchResult(session.ID(), cmp.Or(q.Model, session.ModelID()), q, score))
+		}
+	}
+	return results
+}
+
+// SearchScore conta as ocorrências dos termos no início de palavras da pergunta (valendo o dobro) e
+// da resposta; retorna 0 se algum termo não aparece
+func SearchScore(question, response string, terms []string) int {
+	question, response = foldText(oneLine(question)), foldText(oneLine(response))
+	score := 0
+	for _, term := range terms {
+		hits := 2*len(wordMatches(question, term)) + len(wordMatches(response, term))
+		if hits == 0 {
+			return 0
+		}
+		score += hits
+	}
+	return score
+}
+
+// NewSearchResult monta o resultado da pergunta respondida pelo modelo informado
+func NewSearchResult(sessionID, modelID string, q Question, score int) SearchResult {
+	model := modelID
+	if name, ok := SupportedModels[model]; ok {
+		model = name
+	}
+	return SearchResult{
+		SessionID:  sessionID,
+		Model:      model,
+		QuestionID: q.ID,
+		Timestamp:  q.Timestamp,
+		Question:   q.Text,
+		Response:   q.Response,
+		Score:      score,
+	}
+}
+
+// SortSearchResults ordena pela relevância e, no empate, do mais recente para o mais antigo
+func SortSearchResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+}
+
+// Snippet retorna um trecho da resposta em uma linha, com até width caracteres, em torno do
+// primeiro termo encontrado
+func (r SearchResult) Snippet(terms []string, width int) string {
+	text := []rune(oneLine(r.Response))
+	if len(text) <= width {
+		return string(text)
+	}
+
+	folded := foldText(string(text))
+	first := -1
+	for _, term := range terms {
+		if matches := wordMatches(folded, term); len(matches) > 0 && (first < 0 || matches[0] < first) {
+			first = matches[0]
+		}
+	}
+
+	start := 0
+	if first > width/4 {
+		start = min(first-width/4, len(text)-width)
+	}
+	end := min(start+width, len(text))
+
+	snippet := string(text[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// BuildPromptWithSearchResults acrescenta à pergunta as respostas anteriores trazidas da busca
+func BuildPromptWithSearchResults(text string, results []SearchResult) string {
+	if len(results) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	builder.WriteString(text)
+	for _, r := range results {
+		builder.WriteString(fmt.Sprintf("\n\nConversa anterior (%s, %s, %s):\n", r.Reference(), r.Model, r.Timestamp.Format("02/01/2006")))
+		builder.WriteString(fmt.Sprintf("Pergunta: %s\nResposta: %s", r.Question, r.Response))
+	}
+	return builder.String()
+}
+
+// wordMatches retorna as posições (em runas) em que o termo aparece no início de uma palavra
+func wordMatches(text, term string) []int {
+	runes, target := []rune(text), []rune(term)
+	var positions []int
+	for i := 0; i+len(target) <= len(runes); i++ {
+		if i > 0 && isWordRune(runes[i-1]) {
+			continue
+		}
+		if string(runes[i:i+len(target)]) == term {
+			positions = append(positions, i)
+			i += len(target) - 1
+		}
+	}
+	return positions
+}
+
+// oneLine junta as linhas e espaços repetidos do texto
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// foldText converte para minúsculas e remove acentos, mantendo uma runa por runa
+func foldText(text string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if folded, ok := accentFolding[r]; ok {
+			return folded
+		}
+		return r
+	}, text)
+}
+
+var accentFolding = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
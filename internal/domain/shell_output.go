@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxShellOutputBytes limita a saída de um comando incluída na pergunta
+const MaxShellOutputBytes = 32 * 1024
+
+// ShellCommand é um comando local executado pelo usuário (!comando) cuja saída acompanha a pergunta
+type ShellCommand struct {
+	Command   string        `json:"command"`
+	ExitCode  int           `json:"exit_code"` // -1 quando o comando não terminou (timeout ou falha ao iniciar)
+	Duration  time.Duration `json:"duration"`
+	Truncated bool          `json:"truncated,omitempty"` // A saída enviada foi cortada por exceder o limite
+	Error     string        `json:"error,omitempty"`     // Falha ao executar (timeout, comando inexistente...)
+	Output    string        `json:"-"`                   // Não repetida aqui: vai no Prompt da pergunta, salvo com a sessão
+}
+
+// Describe resume o comando para exibição, ex.: "`go test ./...` (código de saída 1)"
+func (c ShellCommand) Describe() string {
+	status := fmt.Sprintf("código de saída %d", c.ExitCode)
+	if c.Error != "" {
+		status = c.Error
+	}
+	return fmt.Sprintf("`%s` (%s)", c.Command, status)
+}
+
+// BuildPromptWithShellOutputs acrescenta à pergunta a saída de cada comando executado
+func BuildPromptWithShellOutputs(text string, commands []ShellCommand) string {
+	if len(commands) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	builder.WriteString(text)
+	for _, c := range commands {
+		builder.WriteString(fmt.Sprintf("\n\nSaída do comando %s:\n", c.Describe()))
+		fence := codeFence(c.Output)
+		output := c.Output
+		if !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		builder.WriteString(fence + "text\n" + output + fence)
+	}
+	return builder.String()
+}
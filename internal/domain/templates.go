@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Variáveis embutidas disponíveis em todos os templates
+const (
+	TemplateVarDate               = "data"
+	TemplateVarTime               = "hora"
+	TemplateVarDateTime           = "data_hora"
+	TemplateVarModel              = "modelo"
+	TemplateVarLastQuestion       = "ultima_pergunta"
+	TemplateVarLastAnswer         = "ultima_resposta"
+	TemplateFilePrefix            = "arquivo:"
+	maxTemplateFileSize     int64 = 256 * 1024
+)
+
+// templatePlaceholder casa {{variavel}} e {{arquivo:caminho}}
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+(?::[^}]+)?)\s*\}\}`)
+
+// PromptTemplate representa um esqueleto de pergunta com placeholders {{variavel}}
+type PromptTemplate struct {
+	Name    string
+	Path    string
+	Content string
+}
+
+// Variables retorna as variáveis que o usuário precisa informar (exclui as embutidas)
+func (t PromptTemplate) Variables() []string {
+	seen := make(map[string]bool)
+	var variables []string
+
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(t.Content, -1) {
+		name := match[1]
+		if isBuiltinTemplateVar(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		variables = append(variables, name)
+	}
+	return variables
+}
+
+// TemplateLibrary carrega os templates de um diretório (um arquivo por template)
+type TemplateLibrary struct {
+	Dir       string
+	templates map[string]PromptTemplate
+}
+
+// LoadTemplateLibrary lê os templates do diretório; um diretório inexistente resulta em biblioteca vazia
+func LoadTemplateLibrary(dir string) (*TemplateLibrary, error) {
+	library := &TemplateLibrary{Dir: dir, templates: make(map[string]PromptTemplate)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return library, nil
+		}
+		return nil, fmt.Errorf("erro ao ler diretório de templates %s: %v", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler template %s: %v", path, err)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		library.templates[strings.ToLower(name)] = PromptTemplate{
+			Name:    name,
+			Path:    path,
+			Content: string(content),
+		}
+	}
+
+	return library, nil
+}
+
+// List retorna os templates ordenados por nome
+func (l *TemplateLibrary) List() []PromptTemplate {
+	templates := make([]PromptTemplate, 0, len(l.templates))
+	for _, t := range l.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// Get busca um template pelo nome (sem diferenciar maiúsculas)
+func (l *TemplateLibrary) Get(name string) (PromptTemplate, bool) {
+	t, ok := l.templates[strings.ToLower(name)]
+	return t, ok
+}
+
+// RenderTemplate substitui os placeholders pelas variáveis informadas e pelas embutidas.
+// Retorna erro listando todas as variáveis ausentes.
+func RenderTemplate(t PromptTemplate, vars map[string]string, session *ChatSession) (string, error) {
+	var missing []string
+	var renderErr error
+
+	rendered := templatePlaceholder.ReplaceAllStringFunc(t.Content, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+
+		if value, ok := vars[name]; ok {
+			return value
+		}
+
+		value, ok, err := builtinTemplateValue(name, session)
+		if err != nil && renderErr == nil {
+			renderErr = err
+		}
+		if ok {
+			return value
+		}
+
+		missing = append(missing, name)
+		return placeholder
+	})
+
+	if renderErr != nil {
+		return "", fmt.Errorf("template '%s': %v", t.Name, renderErr)
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("template '%s': variáveis não informadas: %s (use %s=valor)", t.Name, strings.Join(uniqueStrings(missing), ", "), missing[0])
+	}
+
+	return rendered, nil
+}
+
+// isBuiltinTemplateVar indica se a variável é preenchida automaticamente
+func isBuiltinTemplateVar(name string) bool {
+	switch name {
+	case TemplateVarDate, TemplateVarTime, TemplateVarDateTime, TemplateVarModel, TemplateVarLastQuestion, TemplateVarLastAnswer:
+		return true
+	}
+	return strings.HasPrefix(name, TemplateFilePrefix)
+}
+
+// builtinTemplateValue resolve uma variável embutida
+func builtinTemplateValue(name string, session *ChatSession) (string, bool, error) {
+	now := time.Now()
+
+	switch name {
+	case TemplateVarDate:
+		return now.Format("02/01/2006"), true, nil
+	case TemplateVarTime:
+		return now.Format("15:04"), true, nil
+	case TemplateVarDateTime:
+		return now.Format("02/01/2006 15:04"), true, nil
+	case TemplateVarModel:
+		if session == nil {
+			return "", false, nil
+		}
+		return session.ModelName(), true, nil
+	case TemplateVarLastQuestion, TemplateVarLastAnswer:
+		last, ok := session.LastSuccessfulQuestion()
+		if !ok {
+			return "", false, fmt.Errorf("{{%s}} requer uma pergunta anterior respondida com sucesso", name)
+		}
+		if name == TemplateVarLastQuestion {
+			return last.Text, true, nil
+		}
+		return last.Response, true, nil
+	}
+
+	if strings.HasPrefix(name, TemplateFilePrefix) {
+		path := strings.TrimSpace(strings.TrimPrefix(name, TemplateFilePrefix))
+		content, err := readTemplateFile(path)
+		if err != nil {
+			return "", false, err
+		}
+		return content, true, nil
+	}
+
+	return "", false, nil
+}
+
+// readTemplateFile lê o conteúdo de um arquivo referenciado por {{arquivo:caminho}}
+func readTemplateFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler arquivo %s: %v", path, err)
+	}
+	if info.Size() > maxTemplateFileSize {
+		return "", fmt.Errorf("arquivo %s excede o limite de %d KB", path, maxTemplateFileSize/1024)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler arquivo %s: %v", path, err)
+	}
+	return string(content), nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
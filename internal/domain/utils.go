@@ -2,6 +2,8 @@ package domain
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -15,7 +17,8 @@ func ListAvailableModels() {
 	cohereModels := make([]string, 0)
 	metaModels := make([]string, 0)
 
-	for modelId, description := range SupportedModels {
+	for _, modelId := range ModelOrder {
+		description := SupportedModels[modelId]
 		if isCohere(modelId) {
 			cohereModels = append(cohereModels, fmt.Sprintf("  %s - %s", modelId, description))
 		} else if isMetaLlama(modelId) {
@@ -36,44 +39,99 @@ func ListAvailableModels() {
 	fmt.Println()
 }
 
-// Função para selecionar modelo interativamente
-func SelectModelInteractively() string {
+// SelectModelInteractively exibe os modelos e pergunta qual usar até receber uma escolha válida.
+// Aceita o número, o ID ou parte do ID ou do nome; buscas parciais pedem confirmação.
+func SelectModelInteractively() (string, error) {
 	ListAvailableModels()
 
 	fmt.Println("Escolha um modelo:")
-	fmt.Println("1. Cohere Command A (Março 2025)")
-	fmt.Println("2. Cohere Command R (Agosto 2024)")
-	fmt.Println("3. Cohere Command R Plus (Agosto 2024)")
-	fmt.Println("4. Meta Llama 3.3 70B Instruct")
-	fmt.Println("5. Meta Llama 3.1 70B Instruct")
-	fmt.Println("6. Meta Llama 3.1 8B Instruct")
-	fmt.Println("7. Meta Llama 2 70B Chat")
-
-	fmt.Print("\nDigite o número do modelo (1-7): ")
-
-	var choice string
-	fmt.Scanln(&choice)
-
-	choiceNum, err := strconv.Atoi(strings.TrimSpace(choice))
-	if err != nil || choiceNum < 1 || choiceNum > 7 {
-		fmt.Println("Escolha inválida. Usando modelo padrão: Meta Llama 3.3 70B")
-		return ModelMetaLlama33_70B
+	printNumberedModels(ModelOrder)
+
+	for {
+		fmt.Printf("\nDigite o número, o ID ou parte do nome do modelo (ex.: llama 8b): ")
+		choice, err := readLine(os.Stdin)
+		choice = strings.TrimSpace(choice)
+		if err != nil && choice == "" {
+			return "", fmt.Errorf("nenhum modelo escolhido")
+		}
+
+		if choice == "" {
+			fmt.Println("⚠️  Escolha um modelo da lista.")
+			continue
+		}
+
+		if n, err := strconv.Atoi(choice); err == nil {
+			if n < 1 || n > len(ModelOrder) {
+				fmt.Printf("⚠️  Número inválido: escolha de 1 a %d.\n", len(ModelOrder))
+				continue
+			}
+			return announceModel(ModelOrder[n-1]), nil
+		}
+
+		matches := FindModels(choice)
+		switch {
+		case len(matches) == 0:
+			fmt.Printf("❌ Nenhum modelo corresponde a \"%s\".\n", choice)
+		case len(matches) > 1:
+			fmt.Printf("🔎 Mais de um modelo corresponde a \"%s\":\n", choice)
+			printNumberedModels(matches)
+			fmt.Println("Digite o número ou detalhe a busca.")
+		case strings.EqualFold(choice, matches[0]):
+			return announceModel(matches[0]), nil
+		default:
+			fmt.Printf("Usar %s (%s)? (S/n): ", SupportedModels[matches[0]], matches[0])
+			answer, _ := readLine(os.Stdin)
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "", "s", "sim", "y", "yes":
+				return announceModel(matches[0]), nil
+			}
+		}
 	}
+}
 
-	models := []string{
-		ModelCohereCommandA03,
-		ModelCohereCommandR08,
-		ModelCohereCommandRPlus08,
-		ModelMetaLlama33_70B,
-		ModelMetaLlama31_70B,
-		ModelMetaLlama31_8B,
-		ModelMetaLlama2_70B,
+// printNumberedModels lista os modelos com o número usado na escolha (posição em ModelOrder)
+func printNumberedModels(ids []string) {
+	for _, id := range ids {
+		for i, candidate := range ModelOrder {
+			if candidate == id {
+				fmt.Printf("%d. %s (%s)\n", i+1, SupportedModels[id], id)
+			}
+		}
 	}
+}
+
+// ConfirmInteractively faz uma pergunta de sim ou não no terminal (padrão: não), antes do chat começar
+func ConfirmInteractively(prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := readLine(os.Stdin)
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "s", "sim", "y", "yes":
+		return true
+	}
+	return false
+}
 
-	selectedModel := models[choiceNum-1]
-	fmt.Printf("Modelo selecionado: %s (%s)\n\n", selectedModel, SupportedModels[selectedModel])
+func announceModel(id string) string {
+	fmt.Printf("Modelo selecionado: %s (%s)\n\n", id, SupportedModels[id])
+	return id
+}
 
-	return selectedModel
+// readLine lê uma linha byte a byte, sem bufferizar, para não consumir a entrada destinada ao chat
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimRight(string(line), "\r"), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
 }
 
 // Função para validar se o modelo é suportado
@@ -8,6 +8,9 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// envFile é o arquivo de configuração lido do diretório atual
+const envFile = ".env"
+
 // OCIConfig contém as configurações necessárias para autenticação no OCI
 type OCIConfig struct {
 	TenancyOCID string
@@ -17,11 +20,19 @@ type OCIConfig struct {
 	Region      string
 }
 
-// LoadConfig carrega a configuração do arquivo .env
+// LoadConfig carrega a configuração do arquivo .env e encerra o programa se for inválida.
+// Mensagens de status usam o pacote log, que escreve em stderr.
 func LoadConfig() OCIConfig {
-	// Verificar se o arquivo .env existe antes de tentar carregá-lo
-	envFile := ".env"
+	cfg, err := ReadConfig()
+	if err != nil {
+		log.Fatalf("❌ Erro na configuração: %v", err)
+	}
+	return cfg
+}
 
+// ReadConfig carrega a configuração do arquivo .env e retorna erro se for inválida
+func ReadConfig() (OCIConfig, error) {
+	// Verificar se o arquivo .env existe antes de tentar carregá-lo
 	if _, err := os.Stat(envFile); err != nil {
 		if os.IsNotExist(err) {
 			log.Printf("⚠️  Arquivo %s não encontrado. Tentando carregar variáveis do ambiente do sistema...", envFile)
@@ -47,10 +58,10 @@ func LoadConfig() OCIConfig {
 
 	// Validar se todas as configurações necessárias estão presentes
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("❌ Erro na configuração: %v", err)
+		return cfg, err
 	}
 
-	return cfg
+	return cfg, nil
 }
 
 // Validate verifica se todas as configurações obrigatórias estão presentes
@@ -79,12 +90,26 @@ func (c *OCIConfig) Validate() error {
 	return nil
 }
 
-// PrintConfig exibe a configuração atual (sem mostrar dados sensíveis)
+// PrintConfig exibe a configuração atual (sem mostrar dados sensíveis).
+// A saída vai para stderr para não misturar com respostas enviadas a stdout.
 func (c *OCIConfig) PrintConfig() {
-	fmt.Println("📋 Configuração OCI carregada:")
-	fmt.Printf("  • Tenancy ID: %s...%s\n", c.TenancyOCID[:20], c.TenancyOCID[len(c.TenancyOCID)-10:])
-	fmt.Printf("  • User ID: %s...%s\n", c.UserOCID[:20], c.UserOCID[len(c.UserOCID)-10:])
-	fmt.Printf("  • Key File: %s\n", c.KeyFile)
-	fmt.Printf("  • Fingerprint: %s\n", c.Fingerprint)
-	fmt.Printf("  • Region: %s\n", c.Region)
+	fmt.Fprintln(os.Stderr, "📋 Configuração OCI carregada:")
+	fmt.Fprintf(os.Stderr, "  • Tenancy ID: %s...%s\n", c.TenancyOCID[:20], c.TenancyOCID[len(c.TenancyOCID)-10:])
+	fmt.Fprintf(os.Stderr, "  • User ID: %s...%s\n", c.UserOCID[:20], c.UserOCID[len(c.UserOCID)-10:])
+	fmt.Fprintf(os.Stderr, "  • Key File: %s\n", c.KeyFile)
+	fmt.Fprintf(os.Stderr, "  • Fingerprint: %s\n", c.Fingerprint)
+	fmt.Fprintf(os.Stderr, "  • Region: %s\n", c.Region)
+}
+
+// DefaultModel retorna o modelo definido em AGENTE_MODEL, no ambiente ou no .env ("" se ausente).
+// Lê o .env diretamente, pois pode ser chamado antes de ReadConfig.
+func DefaultModel() string {
+	if model := os.Getenv("AGENTE_MODEL"); model != "" {
+		return model
+	}
+	values, err := godotenv.Read(envFile)
+	if err != nil {
+		return ""
+	}
+	return values["AGENTE_MODEL"]
 }
@@ -0,0 +1,586 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Os dados gravados em disco (sessões, diários e histórico de entradas) podem ser cifrados com
+// AES-256-GCM. A chave vem de AGENTE_ENCRYPTION_KEY (32 bytes em base64) ou é derivada com scrypt
+// de uma senha (AGENTE_PASSPHRASE, ou digitada no terminal com AGENTE_ENCRYPT_SESSIONS=true).
+// O arquivo encryption.json, no diretório de dados, guarda o sal e um valor de verificação, para
+// que uma chave errada seja recusada antes de qualquer leitura ou gravação.
+
+// encryptedPrefix identifica um conteúdo cifrado: prefixo + base64(nonce || texto cifrado)
+const encryptedPrefix = "agente-encrypted:v1:"
+
+// Parâmetros do scrypt para senhas (recomendação atual para uso interativo)
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keySize      = 32
+	encryptionID = "agente"
+)
+
+// ErrWrongKey indica que a chave não abre os dados cifrados (chave errada ou arquivo alterado)
+var ErrWrongKey = errors.New("chave de criptografia incorreta ou dados corrompidos")
+
+// Cipher cifra e decifra os arquivos gravados pelo agente
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// EncryptionKey é a origem da chave: uma senha (derivada com scrypt) ou a chave pronta
+type EncryptionKey struct {
+	Passphrase string
+	Key        []byte
+}
+
+// encryptionMeta é o conteúdo de encryption.json
+type encryptionMeta struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"` // "scrypt" (senha), "key" (AGENTE_ENCRYPTION_KEY) ou "none" (sendo removida)
+	Salt    []byte `json:"salt,omitempty"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Check   string `json:"check,omitempty"` // encryptionID cifrado com a chave
+}
+
+// EncryptionFile retorna o caminho de encryption.json
+func EncryptionFile() string {
+	return filepath.Join(DataDir(), "encryption.json")
+}
+
+// EncryptionEnabled indica se a criptografia está configurada (encryption.json existe)
+func EncryptionEnabled() bool {
+	meta, err := readEncryptionMeta()
+	return err != nil || meta != nil
+}
+
+// LoadCipher retorna a chave dos dados gravados, ou nil quando não há criptografia configurada.
+// Na primeira vez em que uma chave ou senha é informada, cria encryption.json. askPassphrase é
+// chamada quando é preciso digitar a senha.
+func LoadCipher(askPassphrase func(prompt string) (string, error)) (*Cipher, error) {
+	if err := recoverRekey(); err != nil {
+		return nil, err
+	}
+	meta, err := readEncryptionMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		if key.Passphrase == "" && key.Key == nil && !envBool("AGENTE_ENCRYPT_SESSIONS", false) {
+			return nil, nil
+		}
+		if key.Passphrase == "" && key.Key == nil {
+			if key.Passphrase, err = askNewPassphrase(askPassphrase); err != nil {
+				return nil, err
+			}
+		}
+		return SetupEncryption(key)
+	}
+
+	switch {
+	case meta.KDF == "key" && key.Key == nil:
+		return nil, fmt.Errorf("os dados estão cifrados com uma chave: defina AGENTE_ENCRYPTION_KEY")
+	case meta.KDF == "scrypt" && key.Passphrase == "":
+		if askPassphrase == nil {
+			return nil, fmt.Errorf("os dados estão cifrados com senha: defina AGENTE_PASSPHRASE")
+		}
+		if key.Passphrase, err = askPassphrase("🔐 Senha das sessões: "); err != nil {
+			return nil, err
+		}
+	}
+	return meta.open(key)
+}
+
+// SetupEncryption grava um novo encryption.json para a chave e retorna o Cipher correspondente
+func SetupEncryption(key EncryptionKey) (*Cipher, error) {
+	meta, c, err := newEncryption(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := meta.save(); err != nil {
+		return nil, err
+	}
+	// O índice de busca guarda o texto das sessões; com chave, ele fica só em memória
+	if err := removeSearchIndex(SessionsDir()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// rekeyExt é a extensão das cópias regravadas por Rekey antes de substituírem os originais
+const rekeyExt = ".rekey"
+
+// testHookRekey é chamada a cada etapa de Rekey; nos testes, um erro simula a interrupção do
+// processo naquele ponto, sem desfazer nada
+var testHookRekey = func(stage, path string) error { return nil }
+
+// Rekey regrava sessões, diários e histórico de entradas com a nova chave (nil remove a
+// criptografia). Retorna os arquivos regravados. A troca pode ser interrompida em qualquer ponto
+// sem perder dados (recoverRekey conclui ou desfaz na próxima execução):
+//
+//  1. todos os arquivos são decifrados em memória; se algum não abrir, nada é alterado;
+//  2. os novos metadados vão para encryption.json.rekey e cada arquivo, para <arquivo>.rekey;
+//  3. encryption.json.rekey substitui encryption.json: a partir daqui vale a nova chave;
+//  4. as cópias substituem os originais (e, sem criptografia, encryption.json é apagado).
+func Rekey(current *Cipher, key *EncryptionKey) (int, error) {
+	if err := recoverRekey(); err != nil {
+		return 0, err
+	}
+
+	// Sem chave nova, os metadados pendentes marcam a remoção da criptografia
+	meta := &encryptionMeta{Version: 1, KDF: "none"}
+	var next *Cipher
+	if key != nil {
+		var err error
+		if meta, next, err = newEncryption(*key); err != nil {
+			return 0, err
+		}
+	}
+
+	paths, err := rekeyPaths()
+	if err != nil {
+		return 0, err
+	}
+
+	// Decifrar tudo em memória antes de gravar qualquer arquivo
+	contents := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao ler %s: %v", path, err)
+		}
+		if strings.HasSuffix(path, ".json") {
+			if contents[path], err = current.decode(data); err != nil {
+				return 0, fmt.Errorf("%s: %w", path, err)
+			}
+			continue
+		}
+		if contents[path], err = recodeLines(data, current, next); err != nil {
+			return 0, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	// Preparar: metadados pendentes primeiro, para que cópias sem eles nunca sejam confundidas
+	// com uma troca já confirmada
+	if err := meta.saveTo(EncryptionFile() + rekeyExt); err != nil {
+		return 0, err
+	}
+	for _, path := range paths {
+		data := contents[path]
+		if strings.HasSuffix(path, ".json") {
+			data = next.encode(data)
+		}
+		if err := testHookRekey("stage", path); err != nil {
+			return 0, err
+		}
+		if err := writeFileAtomic(path+rekeyExt, data); err != nil {
+			recoverRekey()
+			return 0, err
+		}
+	}
+
+	// Confirmar
+	if err := testHookRekey("commit", EncryptionFile()); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(EncryptionFile()+rekeyExt, EncryptionFile()); err != nil {
+		recoverRekey()
+		return 0, fmt.Errorf("erro ao gravar %s: %v", EncryptionFile(), err)
+	}
+	syncDir(DataDir())
+	if key != nil {
+		if err := removeSearchIndex(SessionsDir()); err != nil {
+			return 0, err
+		}
+	}
+
+	// Substituir os originais
+	if err := finishRekey(); err != nil {
+		return 0, fmt.Errorf("%w (a troca de chave será concluída na próxima execução)", err)
+	}
+	return len(paths), nil
+}
+
+// recoverRekey conclui ou desfaz uma troca de chave interrompida. Com encryption.json.rekey no
+// disco, a troca não foi confirmada: as cópias são descartadas e os originais continuam valendo.
+// Sem ele, a nova chave já vale e as cópias que sobraram substituem os originais.
+func recoverRekey() error {
+	pending := EncryptionFile() + rekeyExt
+	if _, err := os.Stat(pending); err == nil {
+		staged, err := stagedRekeyFiles()
+		if err != nil {
+			return err
+		}
+		for _, path := range append(staged, pending) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("erro ao desfazer a troca de chave interrompida: %v", err)
+			}
+		}
+		return nil
+	}
+	return finishRekey()
+}
+
+// finishRekey move as cópias regravadas para o lugar dos originais e, se a troca removeu a
+// criptografia, apaga encryption.json por último
+func finishRekey() error {
+	staged, err := stagedRekeyFiles()
+	if err != nil {
+		return err
+	}
+	for _, path := range staged {
+		if err := testHookRekey("swap", path); err != nil {
+			return err
+		}
+		if err := os.Rename(path, strings.TrimSuffix(path, rekeyExt)); err != nil {
+			return fmt.Errorf("erro ao concluir a troca de chave: %v", err)
+		}
+	}
+	if len(staged) > 0 {
+		syncDir(SessionsDir())
+		syncDir(filepath.Dir(HistoryFile()))
+	}
+
+	meta, err := readMetaFile(EncryptionFile())
+	if err != nil || meta == nil || meta.KDF != "none" {
+		return err
+	}
+	return DisableEncryption()
+}
+
+// rekeyPaths lista os arquivos regravados por Rekey: sessões, diários e histórico de entradas
+func rekeyPaths() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(SessionsDir(), "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %v", err)
+	}
+	journals, err := filepath.Glob(filepath.Join(SessionsDir(), "*"+journalExt))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diários de sessão: %v", err)
+	}
+	paths = append(paths, journals...)
+	if _, err := os.Stat(HistoryFile()); err == nil {
+		paths = append(paths, HistoryFile())
+	}
+	return paths, nil
+}
+
+// stagedRekeyFiles lista as cópias deixadas por uma troca de chave
+func stagedRekeyFiles() ([]string, error) {
+	staged, err := filepath.Glob(filepath.Join(SessionsDir(), "*"+rekeyExt))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %v", err)
+	}
+	if _, err := os.Stat(HistoryFile() + rekeyExt); err == nil {
+		staged = append(staged, HistoryFile()+rekeyExt)
+	}
+	return staged, nil
+}
+
+// recodeLines decifra cada linha (diários e histórico) e a cifra com a nova chave
+func recodeLines(data []byte, current, next *Cipher) ([]byte, error) {
+	var out []byte
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		plain, err := current.decode(line)
+		if err != nil && i == len(lines)-1 && errors.Is(err, ErrWrongKey) {
+			break // Última linha incompleta (gravação interrompida)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, next.encode(plain)...)
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+// newEncryption cria os metadados (com sal novo, no caso de senha) e o Cipher da chave
+func newEncryption(key EncryptionKey) (*encryptionMeta, *Cipher, error) {
+	meta := &encryptionMeta{Version: 1, KDF: "key"}
+	if key.Key == nil {
+		meta.KDF, meta.N, meta.R, meta.P = "scrypt", scryptN, scryptR, scryptP
+		meta.Salt = make([]byte, 16)
+		if _, err := rand.Read(meta.Salt); err != nil {
+			return nil, nil, fmt.Errorf("erro ao gerar sal: %v", err)
+		}
+	}
+
+	c, err := meta.cipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta.Check = string(c.Seal([]byte(encryptionID)))
+	return meta, c, nil
+}
+
+// save grava encryption.json
+func (m *encryptionMeta) save() error {
+	return m.saveTo(EncryptionFile())
+}
+
+// saveTo grava os metadados no arquivo indicado
+func (m *encryptionMeta) saveTo(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// DisableEncryption apaga encryption.json; os arquivos devem ter sido regravados sem criptografia
+func DisableEncryption() error {
+	if err := os.Remove(EncryptionFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao apagar %s: %v", EncryptionFile(), err)
+	}
+	return nil
+}
+
+// ParseEncryptionKey decodifica uma chave de 32 bytes em base64 (ex.: openssl rand -base64 32)
+func ParseEncryptionKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("a chave deve ter %d bytes em base64 (gere com: openssl rand -base64 32)", keySize)
+	}
+	return key, nil
+}
+
+// Seal cifra os dados; o resultado é texto de uma linha, seguro para arquivos JSON Lines
+func (c *Cipher) Seal(plain []byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("erro ao gerar nonce: %v", err))
+	}
+	sealed := c.aead.Seal(nonce, nonce, plain, nil)
+	return []byte(encryptedPrefix + base64.StdEncoding.EncodeToString(sealed))
+}
+
+// Open decifra dados gerados por Seal; retorna ErrWrongKey se a chave não corresponder
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	encoded, ok := bytes.CutPrefix(bytes.TrimSpace(data), []byte(encryptedPrefix))
+	if !ok {
+		return nil, fmt.Errorf("os dados não estão cifrados")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plain, nil
+}
+
+// IsEncrypted indica se os dados foram gerados por Cipher.Seal
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(encryptedPrefix))
+}
+
+// decode decifra os dados quando estão cifrados; dados em texto claro são aceitos com ou sem chave
+func (c *Cipher) decode(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, fmt.Errorf("os dados estão cifrados: defina AGENTE_PASSPHRASE ou AGENTE_ENCRYPTION_KEY")
+	}
+	return c.Open(data)
+}
+
+// encode cifra os dados quando há chave (c != nil)
+func (c *Cipher) encode(data []byte) []byte {
+	if c == nil {
+		return data
+	}
+	return c.Seal(data)
+}
+
+// open verifica a chave contra o valor gravado em encryption.json
+func (m *encryptionMeta) open(key EncryptionKey) (*Cipher, error) {
+	c, err := m.cipher(key)
+	if err != nil {
+		return nil, err
+	}
+	check, err := c.Open([]byte(m.Check))
+	if err != nil || string(check) != encryptionID {
+		if m.KDF == "key" {
+			return nil, fmt.Errorf("%w: AGENTE_ENCRYPTION_KEY não abre os dados em %s", ErrWrongKey, DataDir())
+		}
+		return nil, fmt.Errorf("%w: a senha não abre os dados em %s", ErrWrongKey, DataDir())
+	}
+	return c, nil
+}
+
+// cipher deriva a chave (quando é senha) e cria o AES-GCM
+func (m *encryptionMeta) cipher(key EncryptionKey) (*Cipher, error) {
+	raw := key.Key
+	if m.KDF == "scrypt" {
+		derived, err := scrypt.Key([]byte(key.Passphrase), m.Salt, m.N, m.R, m.P, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao derivar chave: %v", err)
+		}
+		raw = derived
+	}
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("a chave deve ter %d bytes", keySize)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar cifra: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar cifra: %v", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// readEncryptionMeta lê encryption.json; retorna nil quando a criptografia não está configurada
+// (ou está sendo removida por Rekey)
+func readEncryptionMeta() (*encryptionMeta, error) {
+	meta, err := readMetaFile(EncryptionFile())
+	if err != nil || meta == nil || meta.KDF == "none" {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// readMetaFile lê um arquivo de metadados; retorna nil quando ele não existe
+func readMetaFile(path string) (*encryptionMeta, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %v", path, err)
+	}
+	var meta encryptionMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%s inválido: %v", path, err)
+	}
+	if meta.KDF != "scrypt" && meta.KDF != "key" && meta.KDF != "none" {
+		return nil, fmt.Errorf("%s: método de chave desconhecido: %s", path, meta.KDF)
+	}
+	return &meta, nil
+}
+
+// keyFromEnv lê a chave ou a senha das variáveis de ambiente
+func keyFromEnv() (EncryptionKey, error) {
+	if value := os.Getenv("AGENTE_ENCRYPTION_KEY"); value != "" {
+		key, err := ParseEncryptionKey(value)
+		if err != nil {
+			return EncryptionKey{}, fmt.Errorf("AGENTE_ENCRYPTION_KEY inválida: %v", err)
+		}
+		return EncryptionKey{Key: key}, nil
+	}
+	return EncryptionKey{Passphrase: os.Getenv("AGENTE_PASSPHRASE")}, nil
+}
+
+// NewEncryptionKey lê a nova chave de AGENTE_NEW_ENCRYPTION_KEY ou AGENTE_NEW_PASSPHRASE; sem
+// nenhuma das duas, pede a nova senha duas vezes (usada por "agente sessions rekey")
+func NewEncryptionKey(askPassphrase func(prompt string) (string, error)) (EncryptionKey, error) {
+	if value := os.Getenv("AGENTE_NEW_ENCRYPTION_KEY"); value != "" {
+		key, err := ParseEncryptionKey(value)
+		if err != nil {
+			return EncryptionKey{}, fmt.Errorf("AGENTE_NEW_ENCRYPTION_KEY inválida: %v", err)
+		}
+		return EncryptionKey{Key: key}, nil
+	}
+	if passphrase := os.Getenv("AGENTE_NEW_PASSPHRASE"); passphrase != "" {
+		return EncryptionKey{Passphrase: passphrase}, nil
+	}
+	passphrase, err := askNewPassphrase(askPassphrase)
+	if err != nil {
+		return EncryptionKey{}, err
+	}
+	return EncryptionKey{Passphrase: passphrase}, nil
+}
+
+// askNewPassphrase pede uma senha nova duas vezes
+func askNewPassphrase(askPassphrase func(prompt string) (string, error)) (string, error) {
+	if askPassphrase == nil {
+		return "", fmt.Errorf("defina AGENTE_PASSPHRASE ou AGENTE_ENCRYPTION_KEY")
+	}
+	passphrase, err := askPassphrase("🔐 Nova senha das sessões: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("a senha não pode ser vazia")
+	}
+	confirmation, err := askPassphrase("🔐 Repita a senha: ")
+	if err != nil {
+		return "", err
+	}
+	if confirmation != passphrase {
+		return "", fmt.Errorf("as senhas não conferem")
+	}
+	return passphrase, nil
+}
+
+// writeFileAtomic grava o arquivo (permissão 0600) substituindo o anterior de uma vez. Os dados
+// vão para o disco antes da renomeação, e a renomeação em seguida, para que uma queda de energia
+// não deixe o arquivo vazio ou truncado.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %v", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", path, err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir grava no disco as renomeações feitas no diretório (sem efeito onde não é suportado)
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
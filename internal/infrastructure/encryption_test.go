@@ -0,0 +1,178 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"agente/internal/domain"
+)
+
+// errInterrupted simula a interrupção do processo durante a troca de chave
+var errInterrupted = errors.New("interrompido")
+
+// rekeyFixture cria, no diretório de dados temporário, sessões, um diário e o histórico de
+// entradas cifrados com oldKey. Retorna os IDs das sessões.
+func rekeyFixture(t *testing.T, oldKey []byte) []string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AGENTE_DATA_DIR", dir)
+	t.Setenv("AGENTE_HISTORY_FILE", filepath.Join(dir, "history"))
+	t.Setenv("AGENTE_PASSPHRASE", "")
+	t.Setenv("AGENTE_ENCRYPT_SESSIONS", "")
+
+	c, err := SetupEncryption(EncryptionKey{Key: oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewSessionStore(SessionsDir(), c)
+	var ids []string
+	for i := range 3 {
+		session := domain.NewChatSession(domain.ModelMetaLlama33_70B, "Llama")
+		session.RecordQuestion(domain.Question{Text: fmt.Sprintf("pergunta %d", i), Response: "resposta", Success: true})
+		if err := store.Save(session); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, session.ID())
+	}
+
+	journal := NewSessionStore(SessionsDir(), c).Journal(domain.NewChatSession(domain.ModelMetaLlama33_70B, "Llama"))
+	if err := journal.Append(domain.Question{ID: 1, Text: "no diário", Success: true}); err != nil {
+		t.Fatal(err)
+	}
+	journal.file.Close()
+
+	history, err := LoadInputHistory(HistoryFile(), DefaultHistoryLimit, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Add("entrada antiga")
+	return ids
+}
+
+// checkReadable abre os dados com a chave e confere que tudo continua legível
+func checkReadable(t *testing.T, key []byte, ids []string) {
+	t.Helper()
+	t.Setenv("AGENTE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(key))
+	if key == nil {
+		t.Setenv("AGENTE_ENCRYPTION_KEY", "")
+	}
+	c, err := LoadCipher(nil)
+	if err != nil {
+		t.Fatalf("LoadCipher: %v", err)
+	}
+	if (c == nil) != (key == nil) {
+		t.Fatalf("LoadCipher retornou chave %v, esperado criptografia = %v", c != nil, key != nil)
+	}
+
+	store := NewSessionStore(SessionsDir(), c)
+	for _, id := range ids {
+		session, err := store.Load(id)
+		if err != nil {
+			t.Fatalf("sessão %s: %v", id, err)
+		}
+		if session.QuestionCount() != 1 {
+			t.Fatalf("sessão %s com %d perguntas, esperado 1", id, session.QuestionCount())
+		}
+	}
+
+	journals, _ := filepath.Glob(filepath.Join(SessionsDir(), "*"+journalExt))
+	if len(journals) != 1 {
+		t.Fatalf("%d diários, esperado 1", len(journals))
+	}
+	content, err := store.readJournal(journals[0])
+	if err != nil || len(content.questions) != 1 || content.questions[0].Text != "no diário" {
+		t.Fatalf("diário ilegível: %+v, %v", content.questions, err)
+	}
+
+	history, err := LoadInputHistory(HistoryFile(), DefaultHistoryLimit, c)
+	if err != nil || history.Len() != 1 || history.At(0) != "entrada antiga" {
+		t.Fatalf("histórico ilegível: %v", err)
+	}
+
+	if staged, _ := stagedRekeyFiles(); len(staged) > 0 {
+		t.Fatalf("cópias da troca de chave não removidas: %v", staged)
+	}
+	if _, err := os.Stat(EncryptionFile() + rekeyExt); err == nil {
+		t.Fatalf("metadados pendentes não removidos")
+	}
+}
+
+func TestRekeyInterrupted(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, keySize)
+	newKey := bytes.Repeat([]byte{2}, keySize)
+
+	tests := []struct {
+		name       string
+		newKey     []byte // nil remove a criptografia
+		stage      string // Etapa interrompida
+		call       int    // Chamada da etapa interrompida (a partir de 1)
+		readableBy []byte
+	}{
+		{"durante a preparação", newKey, "stage", 3, oldKey},
+		{"antes de confirmar", newKey, "commit", 1, oldKey},
+		{"durante a substituição", newKey, "swap", 2, newKey},
+		{"remoção durante a preparação", nil, "stage", 2, oldKey},
+		{"remoção durante a substituição", nil, "swap", 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := rekeyFixture(t, oldKey)
+			t.Setenv("AGENTE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(oldKey))
+			current, err := LoadCipher(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			calls := 0
+			testHookRekey = func(stage, path string) error {
+				if stage == tt.stage {
+					if calls++; calls == tt.call {
+						return errInterrupted
+					}
+				}
+				return nil
+			}
+			var key *EncryptionKey
+			if tt.newKey != nil {
+				key = &EncryptionKey{Key: tt.newKey}
+			}
+			_, err = Rekey(current, key)
+			testHookRekey = func(stage, path string) error { return nil }
+			if !errors.Is(err, errInterrupted) {
+				t.Fatalf("Rekey = %v, esperado a interrupção", err)
+			}
+
+			checkReadable(t, tt.readableBy, ids)
+		})
+	}
+}
+
+func TestRekey(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, keySize)
+	newKey := bytes.Repeat([]byte{2}, keySize)
+	ids := rekeyFixture(t, oldKey)
+	t.Setenv("AGENTE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(oldKey))
+	current, err := LoadCipher(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := Rekey(current, &EncryptionKey{Key: newKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(ids)+2 {
+		t.Errorf("%d arquivos regravados, esperado %d", count, len(ids)+2)
+	}
+	checkReadable(t, newKey, ids)
+
+	t.Setenv("AGENTE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(oldKey))
+	if _, err := LoadCipher(nil); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("a chave antiga ainda abre os dados: %v", err)
+	}
+}
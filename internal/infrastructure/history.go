@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHistoryLimit é a quantidade de entradas mantidas no histórico do REPL
+const DefaultHistoryLimit = 1000
+
+// InputHistory guarda as linhas digitadas no REPL e as persiste em arquivo, uma por linha.
+// Em At, o índice 0 é a entrada mais recente.
+type InputHistory struct {
+	path    string
+	limit   int
+	cipher  *Cipher  // Com chave, cada linha é cifrada separadamente
+	entries []string // Da mais antiga para a mais recente
+}
+
+// LoadInputHistory lê o histórico do arquivo; um arquivo inexistente resulta em histórico vazio
+func LoadInputHistory(path string, limit int, cipher *Cipher) (*InputHistory, error) {
+	history := &InputHistory{path: path, limit: limit, cipher: cipher}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return history, fmt.Errorf("erro ao abrir histórico: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line, err := cipher.decode(scanner.Bytes())
+		if err != nil {
+			// Sem a chave certa o histórico fica só em memória, para não sobrescrever o arquivo
+			history.path, history.entries = "", nil
+			return history, fmt.Errorf("histórico de entradas: %w", err)
+		}
+		if len(line) > 0 {
+			history.entries = append(history.entries, string(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return history, fmt.Errorf("erro ao ler histórico: %v", err)
+	}
+
+	if len(history.entries) > limit {
+		history.entries = history.entries[len(history.entries)-limit:]
+		// Reescreve o arquivo para que ele não cresça indefinidamente
+		if err := history.rewrite(); err != nil {
+			return history, err
+		}
+	}
+	return history, nil
+}
+
+// Add registra uma entrada e a acrescenta ao arquivo. Entradas vazias ou repetidas em sequência são ignoradas.
+func (h *InputHistory) Add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" || strings.Contains(entry, "\n") {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+
+	// Falhas de escrita não interrompem a sessão; o histórico continua em memória
+	_ = h.appendToFile(entry)
+}
+
+// Len retorna a quantidade de entradas
+func (h *InputHistory) Len() int {
+	return len(h.entries)
+}
+
+// At retorna a entrada de índice idx, onde 0 é a mais recente
+func (h *InputHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+func (h *InputHistory) appendToFile(entry string) error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(h.cipher.encode([]byte(entry)), '\n'))
+	return err
+}
+
+func (h *InputHistory) rewrite() error {
+	var content []byte
+	for _, entry := range h.entries {
+		content = append(content, h.cipher.encode([]byte(entry))...)
+		content = append(content, '\n')
+	}
+	if err := os.WriteFile(h.path, content, 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar histórico: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"agente/internal/domain"
+)
+
+// journalExt é a extensão do diário de cada sessão, gravado ao lado do arquivo JSON
+const journalExt = ".journal"
+
+// Journal é o diário (write-ahead log) de uma sessão: cada pergunta concluída é acrescentada em
+// uma linha JSON e sincronizada com o disco antes de seguir. Se o programa terminar sem encerrar a
+// sessão, o diário continua no disco e as perguntas podem ser recuperadas (SessionStore.Recover).
+type Journal struct {
+	path    string
+	session *domain.ChatSession
+	cipher  *Cipher // Cada linha é cifrada separadamente
+	file    *os.File
+}
+
+// journalHeader é a primeira linha do diário: o suficiente para recriar a sessão sem o arquivo JSON
+type journalHeader struct {
+	ID        string    `json:"id"`
+	ModelID   string    `json:"model_id"`
+	ModelName string    `json:"model_name"`
+	StartTime time.Time `json:"start_time"`
+	PID       int       `json:"pid"` // Processo que grava o diário; enquanto ativo, a sessão não é recuperada
+}
+
+// journalEntry é uma linha do diário: o cabeçalho, uma pergunta concluída ou o ID de uma pergunta
+// apagada com /esquecer, que não deve voltar na recuperação
+type journalEntry struct {
+	Session  *journalHeader   `json:"session,omitempty"`
+	Question *domain.Question `json:"question,omitempty"`
+	Forget   int              `json:"forget,omitempty"`
+}
+
+// journalContent é o que foi lido de um diário
+type journalContent struct {
+	header    journalHeader
+	questions []domain.Question
+	forgotten []int
+}
+
+// UnfinishedSession é uma sessão cujo diário ficou no disco
+type UnfinishedSession struct {
+	ID        string
+	UpdatedAt time.Time // Última escrita no diário
+	Questions int       // Perguntas no diário, sem as apagadas
+}
+
+// Journal retorna o diário da sessão; o arquivo só é criado na primeira pergunta
+func (s *SessionStore) Journal(session *domain.ChatSession) *Journal {
+	return &Journal{path: s.journalPath(session.ID()), session: session, cipher: s.cipher}
+}
+
+// Append acrescenta a pergunta ao diário e espera a gravação no disco (fsync)
+func (j *Journal) Append(question domain.Question) error {
+	return j.append(journalEntry{Question: &question})
+}
+
+// AppendForget registra no diário que a pergunta foi apagada, para que a recuperação não a traga de volta
+func (j *Journal) AppendForget(id int) error {
+	return j.append(journalEntry{Forget: id})
+}
+
+// append grava a linha, abrindo o diário na primeira vez
+func (j *Journal) append(entry journalEntry) error {
+	if j.file == nil {
+		if err := j.open(); err != nil {
+			return err
+		}
+	}
+	if err := j.write(j.file, entry); err != nil {
+		return fmt.Errorf("erro ao gravar diário da sessão: %v", err)
+	}
+	return nil
+}
+
+// Remove fecha e apaga o diário, depois que a sessão foi salva no encerramento normal
+func (j *Journal) Remove() error {
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao apagar diário da sessão: %v", err)
+	}
+	return nil
+}
+
+// open abre o diário para acréscimo, gravando o cabeçalho quando o arquivo é novo
+func (j *Journal) open() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de sessões: %v", err)
+	}
+	file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir diário da sessão: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao abrir diário da sessão: %v", err)
+	}
+	if info.Size() == 0 {
+		header := journalHeader{
+			ID:        j.session.ID(),
+			ModelID:   j.session.ModelID(),
+			ModelName: j.session.ModelName(),
+			StartTime: j.session.StartTime(),
+			PID:       os.Getpid(),
+		}
+		if err := j.write(file, journalEntry{Session: &header}); err != nil {
+			file.Close()
+			return fmt.Errorf("erro ao gravar diário da sessão: %v", err)
+		}
+	}
+	j.file = file
+	return nil
+}
+
+// write grava uma linha e sincroniza o arquivo
+func (j *Journal) write(file *os.File, entry journalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(j.cipher.encode(data), '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Unfinished lista as sessões com diário no disco cujo processo já terminou, da mais recente
+// para a mais antiga
+func (s *SessionStore) Unfinished() ([]UnfinishedSession, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+journalExt))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diários de sessão: %v", err)
+	}
+
+	var unfinished []UnfinishedSession
+	for _, path := range paths {
+		content, err := s.readJournal(path)
+		if err != nil || processAlive(content.header.PID) {
+			continue
+		}
+		questions := slices.DeleteFunc(content.questions, func(q domain.Question) bool {
+			return slices.Contains(content.forgotten, q.ID)
+		})
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		unfinished = append(unfinished, UnfinishedSession{
+			ID:        strings.TrimSuffix(filepath.Base(path), journalExt),
+			UpdatedAt: info.ModTime(),
+			Questions: len(questions),
+		})
+	}
+	sort.Slice(unfinished, func(i, j int) bool { return unfinished[i].UpdatedAt.After(unfinished[j].UpdatedAt) })
+	return unfinished, nil
+}
+
+// Resume carrega a sessão salva, como Load; se ela não foi encerrada e o diário ficou no disco,
+// o diário é juntado antes (Recover). Retorna também quantas perguntas vieram do diário.
+func (s *SessionStore) Resume(id string) (*domain.ChatSession, int, error) {
+	resolved, err := s.resolve(id)
+	if err != nil {
+		// A sessão pode existir só no diário, se terminou antes do primeiro salvamento
+		if resolved = strings.TrimSpace(id); !s.unfinished(resolved) {
+			return nil, 0, err
+		}
+	}
+	if s.unfinished(resolved) {
+		return s.Recover(resolved)
+	}
+	session, err := s.read(s.path(resolved))
+	return session, 0, err
+}
+
+// unfinished indica se a sessão tem diário no disco gravado por um processo que já terminou
+func (s *SessionStore) unfinished(id string) bool {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return false
+	}
+	content, err := s.readJournal(s.journalPath(id))
+	return err == nil && !processAlive(content.header.PID)
+}
+
+// Recover junta ao arquivo salvo as perguntas do diário que ainda não estavam nele, sem as apagadas
+// com /esquecer, salva a sessão e apaga o diário. Retorna a sessão e quantas perguntas foram recuperadas.
+func (s *SessionStore) Recover(id string) (*domain.ChatSession, int, error) {
+	content, err := s.readJournal(s.journalPath(id))
+	if err != nil {
+		return nil, 0, err
+	}
+	header := content.header
+
+	var session *domain.ChatSession
+	if _, err := os.Stat(s.path(id)); os.IsNotExist(err) {
+		// A sessão terminou antes do primeiro salvamento: recriar a partir do cabeçalho
+		session = domain.RestoreChatSession(header.ID, header.StartTime, header.ModelID, header.ModelName)
+	} else if session, err = s.read(s.path(id)); err != nil {
+		return nil, 0, err
+	}
+
+	recovered := session.RestoreQuestions(content.questions, content.forgotten)
+	if err := s.Save(session); err != nil {
+		return nil, 0, err
+	}
+	if err := os.Remove(s.journalPath(id)); err != nil && !os.IsNotExist(err) {
+		return nil, 0, fmt.Errorf("erro ao apagar diário da sessão: %v", err)
+	}
+	return session, recovered, nil
+}
+
+// readJournal lê o cabeçalho, as perguntas e os apagamentos do diário. Uma linha incompleta
+// (gravação interrompida) encerra a leitura: o que veio antes dela é válido.
+func (s *SessionStore) readJournal(path string) (journalContent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return journalContent{}, fmt.Errorf("erro ao ler diário da sessão: %v", err)
+	}
+	defer file.Close()
+
+	var header *journalHeader
+	var content journalContent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(domain.MaxTranscriptBytes))
+	for scanner.Scan() {
+		line, err := s.cipher.decode(scanner.Bytes())
+		if err != nil && header == nil {
+			return journalContent{}, fmt.Errorf("diário %s: %w", filepath.Base(path), err)
+		}
+		var entry journalEntry
+		if err != nil || json.Unmarshal(line, &entry) != nil {
+			break
+		}
+		switch {
+		case entry.Session != nil && header == nil:
+			header = entry.Session
+		case entry.Question != nil:
+			content.questions = append(content.questions, *entry.Question)
+		case entry.Forget > 0:
+			content.forgotten = append(content.forgotten, entry.Forget)
+		}
+	}
+	if err := scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return journalContent{}, fmt.Errorf("erro ao ler diário da sessão: %v", err)
+	}
+	if header == nil {
+		return journalContent{}, fmt.Errorf("diário de sessão inválido: %s", path)
+	}
+	content.header = *header
+	return content, nil
+}
+
+// processAlive indica se o processo ainda está em execução
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// No Windows, FindProcess falha quando o processo não existe
+		return true
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+func (s *SessionStore) journalPath(id string) string {
+	return filepath.Join(s.dir, id+journalExt)
+}
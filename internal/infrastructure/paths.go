@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDirName é o nome do diretório da aplicação dentro do diretório de configuração do usuário
+const appDirName = "agente"
+
+// ConfigDir retorna o diretório de configuração do agente (ex.: ~/.config/agente)
+func ConfigDir() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "." + appDirName
+	}
+	return filepath.Join(base, appDirName)
+}
+
+// TemplatesDir retorna o diretório dos templates de pergunta (AGENTE_TEMPLATES_DIR ou <config>/templates)
+func TemplatesDir() string {
+	if dir := os.Getenv("AGENTE_TEMPLATES_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(ConfigDir(), "templates")
+}
+
+// HistoryFile retorna o arquivo com o histórico de entradas do REPL (AGENTE_HISTORY_FILE ou <config>/history)
+func HistoryFile() string {
+	if path := os.Getenv("AGENTE_HISTORY_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(ConfigDir(), "history")
+}
+
+// DataDir retorna o diretório de dados do agente (AGENTE_DATA_DIR, $XDG_DATA_HOME/agente ou ~/.local/share/agente)
+func DataDir() string {
+	if dir := os.Getenv("AGENTE_DATA_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appDirName)
+	}
+	if runtime.GOOS == "windows" {
+		if dir, err := os.UserCacheDir(); err == nil { // %LocalAppData%
+			return filepath.Join(dir, appDirName)
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "." + appDirName
+	}
+	return filepath.Join(home, ".local", "share", appDirName)
+}
+
+// SessionsDir retorna o diretório das sessões salvas
+func SessionsDir() string {
+	return filepath.Join(DataDir(), "sessions")
+}
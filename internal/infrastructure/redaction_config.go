@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultRedactionDetectors são os detectores ativos quando AGENTE_REDACTION_DETECTORS não é definido
+var defaultRedactionDetectors = []string{"pem", "ocid", "email", "cnpj", "cpf", "token"}
+
+// RedactionConfig contém as configurações do mascaramento de dados sensíveis
+type RedactionConfig struct {
+	Enabled        bool              // AGENTE_REDACTION (padrão: true)
+	RestoreAnswers bool              // AGENTE_REDACTION_RESTORE (padrão: false)
+	Detectors      []string          // AGENTE_REDACTION_DETECTORS (lista separada por vírgulas)
+	CustomPatterns map[string]string // AGENTE_REDACTION_PATTERNS_FILE (linhas "nome=regex")
+}
+
+// LoadRedactionConfig lê a configuração de mascaramento das variáveis de ambiente.
+// Deve ser chamada após LoadConfig, que carrega o arquivo .env.
+func LoadRedactionConfig() (RedactionConfig, error) {
+	cfg := RedactionConfig{
+		Enabled:        envBool("AGENTE_REDACTION", true),
+		RestoreAnswers: envBool("AGENTE_REDACTION_RESTORE", false),
+		Detectors:      defaultRedactionDetectors,
+		CustomPatterns: map[string]string{},
+	}
+
+	if list := strings.TrimSpace(os.Getenv("AGENTE_REDACTION_DETECTORS")); list != "" {
+		cfg.Detectors = nil
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Detectors = append(cfg.Detectors, name)
+			}
+		}
+	}
+
+	if path := strings.TrimSpace(os.Getenv("AGENTE_REDACTION_PATTERNS_FILE")); path != "" {
+		patterns, err := loadPatternsFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.CustomPatterns = patterns
+	}
+
+	return cfg, nil
+}
+
+// loadPatternsFile lê padrões customizados no formato "nome=regex", ignorando linhas vazias e comentários
+func loadPatternsFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de padrões %s: %v", path, err)
+	}
+	defer file.Close()
+
+	patterns := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, pattern, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(name) == "" || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("linha %d inválida em %s: use o formato nome=regex", lineNumber, path)
+		}
+		patterns[strings.TrimSpace(name)] = strings.TrimSpace(pattern)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de padrões %s: %v", path, err)
+	}
+	return patterns, nil
+}
+
+// envBool interpreta uma variável de ambiente booleana, usando o padrão quando ausente ou inválida
+func envBool(name string, defaultValue bool) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "sim", "yes", "on":
+		return true
+	case "0", "false", "nao", "não", "no", "off":
+		return false
+	}
+	return defaultValue
+}
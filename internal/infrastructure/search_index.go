@@ -0,0 +1,265 @@
+package infrastructure
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	_ "modernc.org/sqlite" // Driver SQLite em Go puro, sem cgo
+
+	"agente/internal/domain"
+)
+
+// searchIndexFile é o índice de busca das sessões salvas (SQLite com FTS5), no diretório das sessões
+const searchIndexFile = "search.db"
+
+// searchIndexVersion muda quando o esquema muda; um índice de outra versão é recriado
+const searchIndexVersion = 1
+
+// searchSchema guarda cada pergunta respondida, de todos os ramos, e o estado do arquivo de cada
+// sessão quando ela foi indexada. O tokenizador ignora maiúsculas e acentos, como a busca.
+const searchSchema = `
+CREATE TABLE indexed_sessions (
+	id       TEXT PRIMARY KEY,
+	mod_time INTEGER NOT NULL,
+	size     INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE questions USING fts5(
+	session_id UNINDEXED,
+	question_id UNINDEXED,
+	model UNINDEXED,
+	timestamp UNINDEXED,
+	question,
+	response,
+	tokenize = 'unicode61 remove_diacritics 2'
+);`
+
+// searchIndex é o índice de texto completo das sessões. A cada busca, só as sessões alteradas
+// desde a última indexação (data e tamanho do arquivo) são lidas novamente.
+type searchIndex struct {
+	db *sql.DB
+}
+
+// openSearchIndex abre (ou cria) o índice. Com as sessões cifradas, o índice, que guarda o texto
+// das perguntas e respostas, fica só em memória, e um índice em texto claro anterior é apagado.
+func (s *SessionStore) openSearchIndex() (*searchIndex, error) {
+	dsn := ":memory:"
+	if s.cipher != nil {
+		if err := removeSearchIndex(s.dir); err != nil {
+			return nil, err
+		}
+	} else {
+		path := filepath.Join(s.dir, searchIndexFile)
+		if err := os.MkdirAll(s.dir, 0o700); err != nil {
+			return nil, fmt.Errorf("erro ao criar diretório de sessões: %v", err)
+		}
+		// Criado antes do SQLite para ficar legível apenas pelo usuário, como as sessões
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir índice de busca: %v", err)
+		}
+		file.Close()
+		dsn = "file:" + filepath.ToSlash(path) + "?_pragma=busy_timeout(5000)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir índice de busca: %v", err)
+	}
+	db.SetMaxOpenConns(1) // O banco em memória existe só na conexão que o criou
+	index := &searchIndex{db: db}
+	if err := index.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao abrir índice de busca: %v", err)
+	}
+	return index, nil
+}
+
+// removeSearchIndex apaga o índice gravado no diretório das sessões
+func removeSearchIndex(dir string) error {
+	for _, name := range []string{searchIndexFile, searchIndexFile + "-journal"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("erro ao apagar índice de busca: %v", err)
+		}
+	}
+	return nil
+}
+
+// migrate cria as tabelas, recriando o índice quando a versão do esquema é outra
+func (idx *searchIndex) migrate() error {
+	var version int
+	if err := idx.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version == searchIndexVersion {
+		return nil
+	}
+
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	statements := []string{
+		"DROP TABLE IF EXISTS indexed_sessions",
+		"DROP TABLE IF EXISTS questions",
+		searchSchema,
+		fmt.Sprintf("PRAGMA user_version = %d", searchIndexVersion),
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (idx *searchIndex) close() {
+	idx.db.Close()
+}
+
+// sync indexa as sessões novas ou alteradas e remove do índice as apagadas
+func (idx *searchIndex) sync(s *SessionStore) error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("erro ao listar sessões: %v", err)
+	}
+
+	type fileState struct{ modTime, size int64 }
+	indexed := make(map[string]fileState)
+	rows, err := idx.db.Query("SELECT id, mod_time, size FROM indexed_sessions")
+	if err != nil {
+		return fmt.Errorf("erro ao ler índice de busca: %v", err)
+	}
+	for rows.Next() {
+		var id string
+		var state fileState
+		if err := rows.Scan(&id, &state.modTime, &state.size); err != nil {
+			rows.Close()
+			return fmt.Errorf("erro ao ler índice de busca: %v", err)
+		}
+		indexed[id] = state
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao ler índice de busca: %v", err)
+	}
+
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar índice de busca: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		state := fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		previous, ok := indexed[id]
+		delete(indexed, id)
+		if ok && previous == state {
+			continue
+		}
+
+		// Arquivos que a chave não abre interrompem a busca, como em SessionStore.all
+		session, err := s.read(path)
+		if errors.Is(err, ErrWrongKey) {
+			return err
+		}
+		if err := indexSession(tx, id, session); err != nil {
+			return fmt.Errorf("erro ao atualizar índice de busca: %v", err)
+		}
+		if _, err := tx.Exec("INSERT OR REPLACE INTO indexed_sessions (id, mod_time, size) VALUES (?, ?, ?)",
+			id, state.modTime, state.size); err != nil {
+			return fmt.Errorf("erro ao atualizar índice de busca: %v", err)
+		}
+	}
+
+	for id := range indexed {
+		if err := indexSession(tx, id, nil); err != nil {
+			return fmt.Errorf("erro ao atualizar índice de busca: %v", err)
+		}
+		if _, err := tx.Exec("DELETE FROM indexed_sessions WHERE id = ?", id); err != nil {
+			return fmt.Errorf("erro ao atualizar índice de busca: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao atualizar índice de busca: %v", err)
+	}
+	return nil
+}
+
+// indexSession substitui as perguntas da sessão no índice; session nil (arquivo apagado ou
+// ilegível) apenas as remove
+func indexSession(tx *sql.Tx, id string, session *domain.ChatSession) error {
+	if _, err := tx.Exec("DELETE FROM questions WHERE session_id = ?", id); err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+	for _, q := range session.AllQuestions() {
+		if !q.Success {
+			continue
+		}
+		_, err := tx.Exec("INSERT INTO questions (session_id, question_id, model, timestamp, question, response) VALUES (?, ?, ?, ?, ?, ?)",
+			id, q.ID, cmp.Or(q.Model, session.ModelID()), q.Timestamp.Format(time.RFC3339Nano), q.Text, q.Response)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search retorna as perguntas em que todos os termos aparecem. O índice seleciona as candidatas
+// e a pontuação é a mesma da busca em uma sessão (domain.SearchScore).
+func (idx *searchIndex) search(terms []string) ([]domain.SearchResult, error) {
+	rows, err := idx.db.Query("SELECT session_id, question_id, model, timestamp, question, response FROM questions WHERE questions MATCH ?",
+		matchQuery(terms))
+	if err != nil {
+		return nil, fmt.Errorf("erro na busca: %v", err)
+	}
+	defer rows.Close()
+
+	var results []domain.SearchResult
+	for rows.Next() {
+		var sessionID, model, timestamp string
+		var q domain.Question
+		if err := rows.Scan(&sessionID, &q.ID, &model, &timestamp, &q.Text, &q.Response); err != nil {
+			return nil, fmt.Errorf("erro na busca: %v", err)
+		}
+		q.Timestamp, _ = time.Parse(time.RFC3339Nano, timestamp)
+		if score := domain.SearchScore(q.Text, q.Response, terms); score > 0 {
+			results = append(results, domain.NewSearchResult(sessionID, model, q, score))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na busca: %v", err)
+	}
+	return results, nil
+}
+
+// matchQuery monta a consulta FTS5: cada termo é uma frase cuja última palavra pode ser o início
+// de uma palavra do texto ("regi" encontra "região"), e todos precisam aparecer
+func matchQuery(terms []string) string {
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	return strings.Join(phrases, " AND ")
+}
+
+// indexable indica se o índice encontra o termo: termos sem letras nem dígitos (ex.: "->") não
+// formam palavras para o tokenizador
+func indexable(term string) bool {
+	return strings.ContainsFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+}
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"agente/internal/domain"
+)
+
+// searchTexts retorna o texto das perguntas encontradas
+func searchTexts(t *testing.T, store *SessionStore, terms ...string) []string {
+	t.Helper()
+	results, err := store.Search(terms, 0)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", terms, err)
+	}
+	var texts []string
+	for _, r := range results {
+		texts = append(texts, r.Question)
+	}
+	return texts
+}
+
+func TestSearchIndex(t *testing.T) {
+	store := NewSessionStore(t.TempDir(), nil)
+	first := domain.NewChatSession(domain.ModelMetaLlama33_70B, "Llama")
+	first.RecordQuestion(domain.Question{Text: "Qual a região?", Response: "sa-saopaulo-1", Success: true})
+	first.RecordQuestion(domain.Question{Text: "falhou", Response: "região", Success: false})
+	second := domain.NewChatSession(domain.ModelMetaLlama33_70B, "Llama")
+	second.RecordQuestion(domain.Question{Text: "Canais em Go", Response: "use <- para enviar", Success: true})
+	for _, session := range []*domain.ChatSession{first, second} {
+		if err := store.Save(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := searchTexts(t, store, "regiao"); len(got) != 1 || got[0] != "Qual a região?" {
+		t.Fatalf("regiao = %q, esperado só a pergunta respondida", got)
+	}
+	if got := searchTexts(t, store, "<-"); len(got) != 1 || got[0] != "Canais em Go" {
+		t.Fatalf("<- = %q, esperado a busca sem o índice", got)
+	}
+	if _, err := os.Stat(filepath.Join(store.dir, searchIndexFile)); err != nil {
+		t.Fatalf("índice não gravado: %v", err)
+	}
+
+	// Sessões alteradas e apagadas depois da indexação
+	second.RecordQuestion(domain.Question{Text: "E a região de Ashburn?", Response: "us-ashburn-1", Success: true})
+	if err := store.Save(second); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchTexts(t, store, "regi"); len(got) != 2 {
+		t.Fatalf("regi = %q, esperado 2 resultados após alterar a sessão", got)
+	}
+	if err := os.Remove(store.path(first.ID())); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchTexts(t, store, "regiao"); len(got) != 1 || got[0] != "E a região de Ashburn?" {
+		t.Fatalf("regiao = %q, esperado só a sessão restante", got)
+	}
+}
+
+func TestSearchIndexEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	plain := NewSessionStore(dir, nil)
+	if _, err := plain.Search([]string{"texto"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	_, c, err := newEncryption(EncryptionKey{Key: bytes.Repeat([]byte{1}, keySize)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewSessionStore(dir, c)
+	session := domain.NewChatSession(domain.ModelMetaLlama33_70B, "Llama")
+	session.RecordQuestion(domain.Question{Text: "segredo", Response: "texto", Success: true})
+	if err := store.Save(session); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchTexts(t, store, "segredo"); len(got) != 1 {
+		t.Fatalf("segredo = %q, esperado 1 resultado", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, searchIndexFile)); !os.IsNotExist(err) {
+		t.Fatalf("índice em texto claro gravado com a criptografia ativa: %v", err)
+	}
+}
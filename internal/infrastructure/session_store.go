@@ -0,0 +1,221 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"agente/internal/domain"
+)
+
+// LastSession seleciona a sessão salva mais recente em SessionStore.Load
+const LastSession = "last"
+
+// SessionStore grava cada sessão de chat como um arquivo JSON (<id>.json) em um diretório
+type SessionStore struct {
+	dir    string
+	cipher *Cipher // nil grava em texto claro
+}
+
+// NewSessionStore cria o armazenamento no diretório informado (criado no primeiro salvamento).
+// Com cipher, os arquivos são gravados cifrados; arquivos em texto claro continuam legíveis.
+func NewSessionStore(dir string, cipher *Cipher) *SessionStore {
+	return &SessionStore{dir: dir, cipher: cipher}
+}
+
+// SessionsEnabled indica se as sessões devem ser salvas automaticamente (AGENTE_SAVE_SESSIONS, padrão true)
+func SessionsEnabled() bool {
+	return envBool("AGENTE_SAVE_SESSIONS", true)
+}
+
+// Dir retorna o diretório das sessões
+func (s *SessionStore) Dir() string {
+	return s.dir
+}
+
+// Save grava a sessão, substituindo o arquivo de forma atômica.
+// Os arquivos contêm o texto enviado ao modelo (inclusive anexos) e ficam legíveis apenas pelo usuário;
+// com chave configurada, são cifrados.
+func (s *SessionStore) Save(session *domain.ChatSession) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de sessões: %v", err)
+	}
+
+	session.MarkSaved(time.Now())
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar sessão: %v", err)
+	}
+	data = s.cipher.encode(data)
+
+	return writeFileAtomic(s.path(session.ID()), data)
+}
+
+// Load lê uma sessão pelo ID, por um prefixo único do ID ou "last" (a mais recente)
+func (s *SessionStore) Load(id string) (*domain.ChatSession, error) {
+	id, err := s.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.read(s.path(id))
+}
+
+// List retorna o resumo das sessões salvas, da mais recente para a mais antiga.
+// Arquivos que não podem ser lidos são ignorados.
+func (s *SessionStore) List() ([]domain.SessionSummary, error) {
+	sessions, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]domain.SessionSummary, 0, len(sessions))
+	for _, session := range sessions {
+		summaries = append(summaries, session.Summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+	return summaries, nil
+}
+
+// Search busca os termos nas perguntas e respostas de todas as sessões salvas, das mais
+// relevantes para as menos relevantes; limit 0 retorna todos os resultados. A busca usa o índice
+// de texto completo (search.db), atualizado antes com as sessões alteradas.
+func (s *SessionStore) Search(terms []string, limit int) ([]domain.SearchResult, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var results []domain.SearchResult
+	if slices.ContainsFunc(terms, func(term string) bool { return !indexable(term) }) {
+		// Termos só com pontuação não estão no índice: percorrer as sessões
+		sessions, err := s.all()
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			results = append(results, domain.SearchSession(session, terms)...)
+		}
+	} else {
+		index, err := s.openSearchIndex()
+		if err != nil {
+			return nil, err
+		}
+		defer index.close()
+		if err := index.sync(s); err != nil {
+			return nil, err
+		}
+		if results, err = index.search(terms); err != nil {
+			return nil, err
+		}
+	}
+	domain.SortSearchResults(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Dataset reúne as respostas bem avaliadas de todas as sessões salvas, da sessão mais antiga para
+// a mais recente
+func (s *SessionStore) Dataset(filter domain.DatasetFilter) ([]domain.DatasetExample, error) {
+	sessions, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartTime().Before(sessions[j].StartTime()) })
+
+	var examples []domain.DatasetExample
+	for _, session := range sessions {
+		examples = append(examples, session.DatasetExamples(filter)...)
+	}
+	return examples, nil
+}
+
+// all lê todas as sessões salvas, ignorando arquivos que não podem ser lidos. Arquivos que a chave
+// não abre interrompem a leitura, para que o erro não passe despercebido.
+func (s *SessionStore) all() ([]*domain.ChatSession, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %v", err)
+	}
+
+	var sessions []*domain.ChatSession
+	for _, path := range paths {
+		session, err := s.read(path)
+		if errors.Is(err, ErrWrongKey) {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// resolve converte "last" ou um prefixo no ID completo de uma sessão salva
+func (s *SessionStore) resolve(id string) (string, error) {
+	id = strings.TrimSuffix(strings.TrimSpace(id), ".json")
+	if id == "" {
+		return "", fmt.Errorf("informe o ID da sessão ou %q", LastSession)
+	}
+	if strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("ID de sessão inválido: %s", id)
+	}
+	if _, err := os.Stat(s.path(id)); err == nil {
+		return id, nil
+	}
+
+	summaries, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	if len(summaries) == 0 {
+		return "", fmt.Errorf("nenhuma sessão salva em %s", s.dir)
+	}
+	if id == LastSession {
+		return summaries[0].ID, nil
+	}
+
+	var matches []string
+	for _, summary := range summaries {
+		if strings.HasPrefix(summary.ID, id) {
+			matches = append(matches, summary.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("sessão não encontrada: %s", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("mais de uma sessão começa com %s: %s", id, strings.Join(matches, ", "))
+	}
+}
+
+func (s *SessionStore) read(path string) (*domain.ChatSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler sessão: %v", err)
+	}
+	if data, err = s.cipher.decode(data); err != nil {
+		return nil, fmt.Errorf("sessão %s: %w", filepath.Base(path), err)
+	}
+
+	session, err := domain.DecodeChatSession(data, strings.TrimSuffix(filepath.Base(path), ".json"))
+	if err != nil {
+		return nil, fmt.Errorf("sessão inválida em %s: %v", path, err)
+	}
+	return session, nil
+}
+
+func (s *SessionStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+	"unicode/utf8"
+
+	"agente/internal/domain"
+)
+
+// DefaultShellTimeout é o tempo máximo de execução de um !comando
+const DefaultShellTimeout = 60 * time.Second
+
+// ShellTimeout retorna o timeout configurado em AGENTE_SHELL_TIMEOUT (ex.: "2m") ou o padrão
+func ShellTimeout() (time.Duration, error) {
+	value := os.Getenv("AGENTE_SHELL_TIMEOUT")
+	if value == "" {
+		return DefaultShellTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("AGENTE_SHELL_TIMEOUT inválido: %s (use, por exemplo, 30s ou 2m)", value)
+	}
+	return timeout, nil
+}
+
+// RunShellCommand executa o comando no shell do sistema, copiando stdout e stderr para live
+// (se informado) e devolvendo a saída combinada, limitada a domain.MaxShellOutputBytes
+func RunShellCommand(command string, timeout time.Duration, live io.Writer) domain.ShellCommand {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	// Processos filhos que herdam a saída não podem prender a espera após o timeout
+	cmd.WaitDelay = 2 * time.Second
+
+	captured := newHeadTailBuffer(domain.MaxShellOutputBytes)
+	var output io.Writer = captured
+	if live != nil {
+		output = io.MultiWriter(live, captured)
+	}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	start := time.Now()
+	err := cmd.Run()
+	result := domain.ShellCommand{Command: command, Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.Error = fmt.Sprintf("interrompido após %v", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+
+	result.Output = captured.String()
+	result.Truncated = captured.truncated()
+	return result
+}
+
+// headTailBuffer guarda o início e o fim da saída (onde costumam estar os erros),
+// descartando o meio quando ela excede o limite
+type headTailBuffer struct {
+	head      []byte
+	tail      []byte
+	headLimit int
+	tailLimit int
+	dropped   int
+}
+
+func newHeadTailBuffer(limit int) *headTailBuffer {
+	return &headTailBuffer{headLimit: limit / 4, tailLimit: limit - limit/4}
+}
+
+func (b *headTailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.headLimit - len(b.head); room > 0 {
+		take := min(room, len(p))
+		b.head = append(b.head, p[:take]...)
+		p = p[take:]
+	}
+
+	b.tail = append(b.tail, p...)
+	// Descarta o excesso em lotes para não copiar o buffer a cada escrita
+	if len(b.tail) > 2*b.tailLimit {
+		excess := len(b.tail) - b.tailLimit
+		b.dropped += excess
+		b.tail = append(b.tail[:0:0], b.tail[excess:]...)
+	}
+	return n, nil
+}
+
+// String retorna a saída guardada, indicando quantos bytes foram omitidos
+func (b *headTailBuffer) String() string {
+	tail, dropped := b.tail, b.dropped
+	if excess := len(tail) - b.tailLimit; excess > 0 {
+		tail, dropped = tail[excess:], dropped+excess
+	}
+	if dropped == 0 {
+		return string(b.head) + string(tail)
+	}
+
+	// Ajusta os cortes para não quebrar caracteres UTF-8 ao meio
+	head := b.head
+	for len(head) > 0 {
+		if r, size := utf8.DecodeLastRune(head); r != utf8.RuneError || size > 1 {
+			break
+		}
+		head = head[:len(head)-1]
+	}
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+	return fmt.Sprintf("%s\n... [%d bytes omitidos] ...\n%s", head, dropped, tail)
+}
+
+// truncated indica se parte da saída foi descartada
+func (b *headTailBuffer) truncated() bool {
+	return b.dropped > 0 || len(b.tail) > b.tailLimit
+}
@@ -0,0 +1,195 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+)
+
+// language descreve o necessário para destacar a sintaxe de uma linguagem
+type language struct {
+	keywords     map[string]bool
+	lineComment  []string
+	blockComment [2]string
+	quotes       string // Caracteres que abrem strings
+}
+
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	cLike = [2]string{"/*", "*/"}
+
+	languages = map[string]language{
+		"go": {
+			keywords:     words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota"),
+			lineComment:  []string{"//"},
+			blockComment: cLike,
+			quotes:       "\"'`",
+		},
+		"python": {
+			keywords:    words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield None True False self"),
+			lineComment: []string{"#"},
+			quotes:      "\"'",
+		},
+		"javascript": {
+			keywords:     words("async await break case catch class const continue debugger default delete do else export extends finally for function if import in instanceof let new of return static super switch this throw try typeof var void while yield null undefined true false interface type enum implements"),
+			lineComment:  []string{"//"},
+			blockComment: cLike,
+			quotes:       "\"'`",
+		},
+		"java": {
+			keywords:     words("abstract boolean break byte case catch char class const continue default do double else enum extends final finally float for if implements import instanceof int interface long new package private protected public return short static super switch synchronized this throw throws try void volatile while null true false var record"),
+			lineComment:  []string{"//"},
+			blockComment: cLike,
+			quotes:       "\"'",
+		},
+		"c": {
+			keywords:     words("auto break case char const continue default do double else enum extern float for goto if inline int long register return short signed sizeof static struct switch typedef union unsigned void volatile while class namespace template typename public private protected virtual new delete nullptr true false bool using"),
+			lineComment:  []string{"//"},
+			blockComment: cLike,
+			quotes:       "\"'",
+		},
+		"rust": {
+			keywords:     words("as async await break const continue crate else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while"),
+			lineComment:  []string{"//"},
+			blockComment: cLike,
+			quotes:       "\"",
+		},
+		"shell": {
+			keywords:    words("if then else elif fi case esac for while until do done in function return export local readonly echo exit set unset source"),
+			lineComment: []string{"#"},
+			quotes:      "\"'",
+		},
+		"sql": {
+			keywords:     words("select from where and or not insert into values update set delete create table alter drop index join left right inner outer on group by order having limit offset as distinct union all null is in like between case when then else end primary key foreign references default with returning SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE ALTER DROP INDEX JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT OFFSET AS DISTINCT UNION ALL NULL IS IN LIKE BETWEEN CASE WHEN THEN ELSE END PRIMARY KEY FOREIGN REFERENCES DEFAULT WITH RETURNING"),
+			lineComment:  []string{"--"},
+			blockComment: cLike,
+			quotes:       "'\"",
+		},
+		"yaml": {
+			keywords:    words("true false null yes no"),
+			lineComment: []string{"#"},
+			quotes:      "\"'",
+		},
+		"json": {
+			keywords: words("true false null"),
+			quotes:   "\"",
+		},
+	}
+
+	// languageAliases mapeia o identificador do bloco ``` para a linguagem
+	languageAliases = map[string]string{
+		"golang": "go", "py": "python", "python3": "python",
+		"js": "javascript", "jsx": "javascript", "ts": "javascript", "tsx": "javascript", "typescript": "javascript",
+		"kotlin": "java", "kt": "java", "csharp": "java", "cs": "java", "c#": "java",
+		"cpp": "c", "c++": "c", "h": "c", "hpp": "c",
+		"rs": "rust",
+		"sh": "shell", "bash": "shell", "zsh": "shell", "console": "shell",
+		"postgresql": "sql", "mysql": "sql", "plsql": "sql",
+		"yml": "yaml",
+	}
+)
+
+// highlighter destaca linhas de código, mantendo o estado de comentários de várias linhas
+type highlighter struct {
+	lang      language
+	known     bool
+	inComment bool
+}
+
+func newHighlighter(name string) *highlighter {
+	name = strings.ToLower(name)
+	if alias, ok := languageAliases[name]; ok {
+		name = alias
+	}
+	lang, ok := languages[name]
+	return &highlighter{lang: lang, known: ok}
+}
+
+// Line retorna a linha com cores ANSI; linguagens desconhecidas ficam sem destaque
+func (h *highlighter) Line(line string) string {
+	if !h.known {
+		return line
+	}
+
+	var out strings.Builder
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:])
+
+		// Continuação ou início de comentário de bloco
+		if h.inComment || (h.lang.blockComment[0] != "" && strings.HasPrefix(rest, h.lang.blockComment[0])) {
+			start := 0
+			if !h.inComment {
+				start = len(h.lang.blockComment[0])
+			}
+			end := strings.Index(rest[start:], h.lang.blockComment[1])
+			if end < 0 {
+				h.inComment = true
+				out.WriteString(styleComment.apply(rest))
+				break
+			}
+			h.inComment = false
+			comment := rest[:start+end+len(h.lang.blockComment[1])]
+			out.WriteString(styleComment.apply(comment))
+			i += len([]rune(comment))
+			continue
+		}
+
+		if h.isLineComment(rest) {
+			out.WriteString(styleComment.apply(rest))
+			break
+		}
+
+		r := runes[i]
+		switch {
+		case strings.ContainsRune(h.lang.quotes, r):
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(runes))
+			out.WriteString(styleString.apply(string(runes[i:j])))
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || unicode.IsLetter(runes[j]) || runes[j] == '.' || runes[j] == '_') {
+				j++
+			}
+			out.WriteString(styleNumber.apply(string(runes[i:j])))
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			word := string(runes[i:j])
+			if h.lang.keywords[word] {
+				word = styleKeyword.apply(word)
+			}
+			out.WriteString(word)
+			i = j
+		default:
+			out.WriteRune(r)
+			i++
+		}
+	}
+	return out.String()
+}
+
+func (h *highlighter) isLineComment(rest string) bool {
+	for _, prefix := range h.lang.lineComment {
+		if strings.HasPrefix(rest, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// HTML converte o Markdown em HTML, reconhecendo os mesmos blocos e a mesma formatação em linha
+// do terminal. Todo o texto é escapado: HTML presente na resposta aparece como texto.
+func HTML(markdown string) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	return strings.Join(htmlBlocks(lines), "\n")
+}
+
+// htmlBlocks identifica os blocos de Markdown, como renderBlocks, e os converte em elementos HTML
+func htmlBlocks(lines []string) []string {
+	var out []string
+
+	for i := 0; i < len(lines); {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			var block string
+			block, i = htmlCodeBlock(lines, i)
+			out = append(out, block)
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			out = append(out, fmt.Sprintf("<h%d>%s</h%d>", len(m[1]), htmlInline(m[2]), len(m[1])))
+			i++
+
+		case rulePattern.MatchString(line):
+			out = append(out, "<hr>")
+			i++
+
+		case isTableStart(lines, i):
+			var table string
+			table, i = htmlTable(lines, i)
+			out = append(out, table)
+
+		case quotePattern.MatchString(line):
+			var quoted []string
+			for i < len(lines) && quotePattern.MatchString(lines[i]) {
+				quoted = append(quoted, quotePattern.FindStringSubmatch(lines[i])[1])
+				i++
+			}
+			out = append(out, "<blockquote>\n"+strings.Join(htmlBlocks(quoted), "\n")+"\n</blockquote>")
+
+		case listPattern.MatchString(line):
+			var list string
+			list, i = htmlList(lines, i)
+			out = append(out, list)
+
+		default:
+			var paragraph string
+			paragraph, i = htmlParagraph(lines, i)
+			out = append(out, paragraph)
+		}
+	}
+	return out
+}
+
+// htmlParagraph junta as linhas do parágrafo; "  " ou "\" no fim forçam quebra de linha
+func htmlParagraph(lines []string, i int) (string, int) {
+	var parts []string
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if len(parts) > 0 && startsBlock(lines, i) {
+			break
+		}
+		line := lines[i]
+		text := htmlInline(strings.TrimSpace(strings.TrimSuffix(line, "\\")))
+		if strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\") {
+			text += "<br>"
+		}
+		parts = append(parts, text)
+	}
+	return "<p>" + strings.Join(parts, "\n") + "</p>", i
+}
+
+// htmlList agrupa os itens consecutivos em listas, aninhadas conforme o recuo
+func htmlList(lines []string, i int) (string, int) {
+	type level struct {
+		indent int
+		tag    string
+	}
+	var b strings.Builder
+	var stack []level
+
+	for i < len(lines) {
+		m := listPattern.FindStringSubmatch(strings.ReplaceAll(lines[i], "\t", "    "))
+		if m == nil || rulePattern.MatchString(lines[i]) {
+			break
+		}
+		indent, marker, text := len(m[1]), m[2], m[3]
+		tag := "ul"
+		if !strings.ContainsAny(marker, "-*+") {
+			tag = "ol"
+		}
+
+		// Fecha as listas mais internas (ou de outro tipo no mesmo nível)
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if indent > top.indent || indent == top.indent && tag == top.tag {
+				break
+			}
+			b.WriteString("</li></" + top.tag + ">\n")
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 || indent > stack[len(stack)-1].indent {
+			start := ""
+			if n, err := strconv.Atoi(strings.TrimRight(marker, ".)")); err == nil && n != 1 {
+				start = fmt.Sprintf(` start="%d"`, n)
+			}
+			b.WriteString("<" + tag + start + ">\n")
+			stack = append(stack, level{indent: indent, tag: tag})
+		} else {
+			b.WriteString("</li>\n")
+		}
+
+		checkbox := ""
+		if task := taskPattern.FindStringSubmatch(text); task != nil {
+			checkbox, text = `<input type="checkbox" disabled> `, text[len(task[0]):]
+			if task[1] != " " {
+				checkbox = `<input type="checkbox" checked disabled> `
+			}
+		}
+
+		// Linhas seguintes que não iniciam outro bloco continuam o item
+		parts := []string{text}
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines, i); i++ {
+			parts = append(parts, strings.TrimSpace(lines[i]))
+		}
+		b.WriteString("<li>" + checkbox + htmlInline(strings.Join(parts, " ")))
+
+		// Linhas em branco entre itens não encerram a lista
+		for i+1 < len(lines) && strings.TrimSpace(lines[i]) == "" && listPattern.MatchString(lines[i+1]) {
+			i++
+		}
+	}
+
+	for j := len(stack) - 1; j >= 0; j-- {
+		b.WriteString("</li></" + stack[j].tag + ">")
+		if j > 0 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), i
+}
+
+// htmlCodeBlock converte um bloco ``` em <pre>, com a linguagem na classe do <code>
+func htmlCodeBlock(lines []string, i int) (string, int) {
+	m := fencePattern.FindStringSubmatch(lines[i])
+	indent, fence, lang := len(m[1]), m[2], m[3]
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line = line[min(indent, len(line)-len(strings.TrimLeft(line, " "))):]
+		code = append(code, html.EscapeString(line))
+	}
+
+	class := ""
+	if lang != "" {
+		class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(lang))
+	}
+	return fmt.Sprintf("<pre><code%s>%s</code></pre>", class, strings.Join(code, "\n")), i
+}
+
+// htmlTable converte a tabela, mantendo o alinhamento das colunas (:--, :-:, --:)
+func htmlTable(lines []string, i int) (string, int) {
+	header := splitRow(lines[i])
+	aligns := splitRow(lines[i+1])
+
+	var b strings.Builder
+	row := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for c := range header {
+			text, align := "", ""
+			if c < len(cells) {
+				text = cells[c]
+			}
+			if c < len(aligns) {
+				switch a := aligns[c]; {
+				case strings.HasPrefix(a, ":") && strings.HasSuffix(a, ":"):
+					align = ` style="text-align: center"`
+				case strings.HasSuffix(a, ":"):
+					align = ` style="text-align: right"`
+				}
+			}
+			b.WriteString(fmt.Sprintf("<%s%s>%s</%s>", tag, align, htmlInline(text), tag))
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	row(header, "th")
+	b.WriteString("</thead>\n<tbody>\n")
+	for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		row(splitRow(lines[i]), "td")
+	}
+	b.WriteString("</tbody>\n</table>")
+	return b.String(), i
+}
+
+// htmlInline converte a formatação em linha, com as mesmas regras de parseInline.
+// Links só viram <a> com http, https ou mailto; os demais ficam como texto.
+func htmlInline(text string) string {
+	var b strings.Builder
+	var plain strings.Builder
+
+	flush := func() {
+		b.WriteString(html.EscapeString(plain.String()))
+		plain.Reset()
+	}
+	emit := func(element string) {
+		flush()
+		b.WriteString(element)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#+-.!~|<>", rune(rest[1])):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			n := len(rest) - len(strings.TrimLeft(rest, "`"))
+			if end := strings.Index(rest[n:], rest[:n]); end >= 0 {
+				emit("<code>" + html.EscapeString(strings.TrimSpace(rest[n:n+end])) + "</code>")
+				i += n + end + n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__") || strings.HasPrefix(rest, "~~"):
+			delim := rest[:2]
+			end := strings.Index(rest[2:], delim)
+			if end > 0 && (delim != "__" || isBoundary(text, i, i+2+end+2)) {
+				tag := "strong"
+				if delim == "~~" {
+					tag = "del"
+				}
+				emit("<" + tag + ">" + htmlInline(rest[2:2+end]) + "</" + tag + ">")
+				i += end + 4
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end > 0 && rest[1] != ' ' && rest[end] != ' ' && (rest[0] == '*' || isBoundary(text, i, i+end+2)) {
+				emit("<em>" + htmlInline(rest[1:1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '[':
+			if m := linkPattern.FindStringSubmatch(rest); m != nil {
+				label, url := m[1], m[2]
+				if isSafeURL(url) {
+					emit(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), htmlInline(label)))
+				} else {
+					emit(htmlInline(label) + " (" + html.EscapeString(url) + ")")
+				}
+				i += len(m[0])
+				continue
+			}
+		}
+
+		plain.WriteByte(text[i])
+		i++
+	}
+	flush()
+	return b.String()
+}
+
+// isSafeURL aceita apenas links http, https e mailto
+func isSafeURL(url string) bool {
+	lower := strings.ToLower(url)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:")
+}
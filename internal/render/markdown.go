@@ -0,0 +1,475 @@
+// Package render converte as respostas em Markdown dos modelos em texto formatado
+// para o terminal: títulos, listas, tabelas, citações e blocos de código com destaque.
+// HTML gera a versão usada na exportação das sessões.
+package render
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultWidth é usada quando a largura do terminal não é conhecida
+const DefaultWidth = 80
+
+// style é um código SGR do ANSI, ex.: "1" (negrito) ou "1;35"
+type style string
+
+const (
+	styleBold    style = "1"
+	styleDim     style = "2"
+	styleItalic  style = "3"
+	styleStrike  style = "9"
+	styleCode    style = "33"
+	styleLink    style = "4;34"
+	styleKeyword style = "35"
+	styleString  style = "32"
+	styleNumber  style = "33"
+	styleComment style = "90"
+	styleBorder  style = "90"
+)
+
+// headingStyles define o estilo de cada nível de título (#, ##, ### ...)
+var headingStyles = []style{"1;4;35", "1;36", "1;34", "1", "1", "1"}
+
+func (s style) with(other style) style {
+	if s == "" {
+		return other
+	}
+	return s + ";" + other
+}
+
+func (s style) apply(text string) string {
+	if s == "" || text == "" {
+		return text
+	}
+	return "\033[" + string(s) + "m" + text + "\033[0m"
+}
+
+var (
+	headingPattern   = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	rulePattern      = regexp.MustCompile(`^\s{0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	fencePattern     = regexp.MustCompile("^(\\s*)(`{3,}|~{3,})\\s*([^`\\s]*)")
+	listPattern      = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	quotePattern     = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	separatorPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	linkPattern      = regexp.MustCompile(`^\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	taskPattern      = regexp.MustCompile(`^\[([ xX])\]\s+`)
+)
+
+// Renderer formata Markdown para o terminal. Um Renderer nil devolve o texto sem alterações.
+type Renderer struct {
+	width func() int
+}
+
+// New cria um renderizador; width informa a largura atual do terminal (0 usa DefaultWidth)
+func New(width func() int) *Renderer {
+	return &Renderer{width: width}
+}
+
+// Render formata o Markdown com cores ANSI, quebrando o texto na largura do terminal
+func (r *Renderer) Render(markdown string) string {
+	if r == nil {
+		return markdown
+	}
+
+	width := DefaultWidth
+	if r.width != nil {
+		if w := r.width(); w > 0 {
+			width = w
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	return strings.TrimRight(strings.Join(renderBlocks(lines, width), "\n"), "\n ")
+}
+
+// renderBlocks identifica e formata os blocos de Markdown, linha a linha
+func renderBlocks(lines []string, width int) []string {
+	var out []string
+	blank := false
+
+	for i := 0; i < len(lines); {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			i++
+			continue
+
+		case fencePattern.MatchString(line):
+			var block []string
+			block, i = renderCodeBlock(lines, i, width)
+			out = append(out, block...)
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			level := len(m[1])
+			out = append(out, wrapWords(styledWords(parseInline(m[2], headingStyles[level-1])), width)...)
+			i++
+
+		case rulePattern.MatchString(line):
+			out = append(out, styleBorder.apply(strings.Repeat("─", width)))
+			i++
+
+		case isTableStart(lines, i):
+			var table []string
+			table, i = renderTable(lines, i, width)
+			out = append(out, table...)
+
+		case quotePattern.MatchString(line):
+			var quoted []string
+			for i < len(lines) && quotePattern.MatchString(lines[i]) {
+				quoted = append(quoted, quotePattern.FindStringSubmatch(lines[i])[1])
+				i++
+			}
+			for _, l := range renderBlocks(quoted, width-2) {
+				out = append(out, styleBorder.apply("│")+" "+l)
+			}
+
+		case listPattern.MatchString(line):
+			var item []string
+			item, i = renderListItem(lines, i, width)
+			out = append(out, item...)
+
+		default:
+			var paragraph []string
+			paragraph, i = renderParagraph(lines, i, width)
+			out = append(out, paragraph...)
+		}
+		blank = false
+	}
+	return out
+}
+
+// startsBlock indica se a linha inicia um bloco diferente de parágrafo
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	return fencePattern.MatchString(line) || headingPattern.MatchString(line) || rulePattern.MatchString(line) ||
+		quotePattern.MatchString(line) || listPattern.MatchString(line) || isTableStart(lines, i)
+}
+
+// renderParagraph junta as linhas do parágrafo e as quebra na largura; "  " ou "\" no fim forçam quebra
+func renderParagraph(lines []string, i, width int) ([]string, int) {
+	var out []string
+	var text []string
+
+	flush := func() {
+		if len(text) > 0 {
+			out = append(out, wrapWords(styledWords(parseInline(strings.Join(text, " "), "")), width)...)
+			text = nil
+		}
+	}
+
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if len(text) > 0 && startsBlock(lines, i) {
+			break
+		}
+		line := lines[i]
+		hardBreak := strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\")
+		text = append(text, strings.TrimSpace(strings.TrimSuffix(line, "\\")))
+		if hardBreak {
+			flush()
+		}
+	}
+	flush()
+	return out, i
+}
+
+// renderListItem formata um item de lista com recuo e marcador, incluindo linhas de continuação
+func renderListItem(lines []string, i, width int) ([]string, int) {
+	m := listPattern.FindStringSubmatch(strings.ReplaceAll(lines[i], "\t", "    "))
+	indent, marker, text := len(m[1]), m[2], m[3]
+
+	bullet := marker
+	if strings.ContainsAny(marker, "-*+") {
+		bullet = []string{"•", "◦", "▪"}[min(indent/2, 2)]
+	}
+	if task := taskPattern.FindStringSubmatch(text); task != nil {
+		bullet, text = "☐", text[len(task[0]):]
+		if task[1] != " " {
+			bullet = "☑"
+		}
+	}
+
+	// Linhas seguintes que não iniciam outro bloco continuam o item
+	parts := []string{text}
+	for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines, i); i++ {
+		parts = append(parts, strings.TrimSpace(lines[i]))
+	}
+
+	prefix := strings.Repeat(" ", indent) + styleBold.apply(bullet) + " "
+	hanging := strings.Repeat(" ", indent+displayWidth(bullet)+1)
+	wrapped := wrapWords(styledWords(parseInline(strings.Join(parts, " "), "")), max(width-len(hanging), 10))
+
+	out := make([]string, len(wrapped))
+	for j, l := range wrapped {
+		if j == 0 {
+			out[j] = prefix + l
+		} else {
+			out[j] = hanging + l
+		}
+	}
+	return out, i
+}
+
+// renderCodeBlock formata um bloco ``` com moldura e destaque de sintaxe; o código não é quebrado
+func renderCodeBlock(lines []string, i, width int) ([]string, int) {
+	m := fencePattern.FindStringSubmatch(lines[i])
+	indent, fence, lang := len(m[1]), m[2], m[3]
+	highlighter := newHighlighter(lang)
+
+	title := "─"
+	if lang != "" {
+		title = "─ " + lang + " "
+	}
+	out := []string{styleBorder.apply("┌" + title + strings.Repeat("─", max(width-displayWidth(title)-1, 0)))}
+
+	for i++; i < len(lines); i++ {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		// Remove o recuo do bloco (ex.: código dentro de um item de lista)
+		line = line[min(indent, len(line)-len(strings.TrimLeft(line, " "))):]
+		out = append(out, styleBorder.apply("│")+" "+highlighter.Line(line))
+	}
+
+	out = append(out, styleBorder.apply("└"+strings.Repeat("─", max(width-1, 0))))
+	return out, i
+}
+
+// isTableStart indica se a linha é o cabeçalho de uma tabela (seguido da linha de alinhamento)
+func isTableStart(lines []string, i int) bool {
+	return strings.Contains(lines[i], "|") && i+1 < len(lines) &&
+		strings.Contains(lines[i+1], "-") && separatorPattern.MatchString(lines[i+1])
+}
+
+// renderTable desenha a tabela com bordas, alinhando as colunas e quebrando células se necessário
+func renderTable(lines []string, i, width int) ([]string, int) {
+	header := splitRow(lines[i])
+	aligns := splitRow(lines[i+1])
+	rows := [][]string{header}
+	for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		rows = append(rows, splitRow(lines[i]))
+	}
+
+	columns := len(header)
+	cells := make([][][]string, len(rows)) // linha → coluna → palavras estilizadas
+	widths := make([]int, columns)
+	for r, row := range rows {
+		cells[r] = make([][]string, columns)
+		for c := 0; c < columns; c++ {
+			var base style
+			if r == 0 {
+				base = styleBold
+			}
+			if c < len(row) {
+				cells[r][c] = styledWords(parseInline(row[c], base))
+			}
+			widths[c] = max(widths[c], displayWidth(strings.Join(cells[r][c], " ")))
+		}
+	}
+	fitColumns(widths, width-3*columns-1)
+
+	border := func(left, middle, right string) string {
+		parts := make([]string, columns)
+		for c, w := range widths {
+			parts[c] = strings.Repeat("─", w+2)
+		}
+		return styleBorder.apply(left + strings.Join(parts, middle) + right)
+	}
+
+	out := []string{border("┌", "┬", "┐")}
+	for r := range rows {
+		// Cada célula pode ocupar várias linhas quando a tabela não cabe no terminal
+		wrapped := make([][]string, columns)
+		height := 1
+		for c := range columns {
+			wrapped[c] = wrapWords(cells[r][c], widths[c])
+			height = max(height, len(wrapped[c]))
+		}
+		for l := range height {
+			var b strings.Builder
+			b.WriteString(styleBorder.apply("│"))
+			for c := range columns {
+				text := ""
+				if l < len(wrapped[c]) {
+					text = wrapped[c][l]
+				}
+				align := ""
+				if c < len(aligns) {
+					align = aligns[c]
+				}
+				b.WriteString(" " + alignCell(text, widths[c], align) + " " + styleBorder.apply("│"))
+			}
+			out = append(out, b.String())
+		}
+		if r == 0 {
+			out = append(out, border("├", "┼", "┤"))
+		}
+	}
+	out = append(out, border("└", "┴", "┘"))
+	return out, i
+}
+
+// splitRow separa as células de uma linha da tabela, ignorando as barras das bordas
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(strings.TrimSuffix(line, "|"), "|")
+
+	var cells []string
+	var current strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(current.String()))
+}
+
+// fitColumns reduz as colunas mais largas até a tabela caber na largura disponível
+func fitColumns(widths []int, available int) {
+	for {
+		total, widest := 0, 0
+		for c, w := range widths {
+			total += w
+			if w > widths[widest] {
+				widest = c
+			}
+		}
+		if total <= available || widths[widest] <= 8 {
+			return
+		}
+		widths[widest]--
+	}
+}
+
+// alignCell posiciona o texto na célula conforme o alinhamento da coluna (:--, :-:, --:)
+func alignCell(text string, width int, align string) string {
+	space := max(width-displayWidth(text), 0)
+	switch {
+	case strings.HasPrefix(align, ":") && strings.HasSuffix(align, ":"):
+		return strings.Repeat(" ", space/2) + text + strings.Repeat(" ", space-space/2)
+	case strings.HasSuffix(align, ":"):
+		return strings.Repeat(" ", space) + text
+	}
+	return padRight(text, width)
+}
+
+// segment é um trecho de texto com estilo uniforme
+type segment struct {
+	text  string
+	style style
+}
+
+// parseInline interpreta a formatação em linha: **negrito**, *itálico*, ~~riscado~~, `código` e [links](url)
+func parseInline(text string, base style) []segment {
+	var segments []segment
+	var plain strings.Builder
+
+	flush := func() {
+		if plain.Len() > 0 {
+			segments = append(segments, segment{text: plain.String(), style: base})
+			plain.Reset()
+		}
+	}
+	emit := func(inner []segment) {
+		flush()
+		segments = append(segments, inner...)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_[]()#+-.!~|<>", rune(rest[1])):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			n := len(rest) - len(strings.TrimLeft(rest, "`"))
+			if end := strings.Index(rest[n:], rest[:n]); end >= 0 {
+				emit([]segment{{text: strings.TrimSpace(rest[n : n+end]), style: base.with(styleCode)}})
+				i += n + end + n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__") || strings.HasPrefix(rest, "~~"):
+			delim := rest[:2]
+			end := strings.Index(rest[2:], delim)
+			if end > 0 && (delim != "__" || isBoundary(text, i, i+2+end+2)) {
+				emphasis := styleBold
+				if delim == "~~" {
+					emphasis = styleStrike
+				}
+				emit(parseInline(rest[2:2+end], base.with(emphasis)))
+				i += end + 4
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end > 0 && rest[1] != ' ' && rest[end] != ' ' && (rest[0] == '*' || isBoundary(text, i, i+end+2)) {
+				emit(parseInline(rest[1:1+end], base.with(styleItalic)))
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '[':
+			if m := linkPattern.FindStringSubmatch(rest); m != nil {
+				label, url := m[1], m[2]
+				emit(parseInline(label, base.with(styleLink)))
+				if url != label {
+					segments = append(segments, segment{text: " (" + url + ")", style: base.with(styleDim)})
+				}
+				i += len(m[0])
+				continue
+			}
+		}
+
+		plain.WriteByte(text[i])
+		i++
+	}
+	flush()
+	return segments
+}
+
+// isBoundary indica se os delimitadores em start e end não estão no meio de uma palavra (ex.: snake_case)
+func isBoundary(text string, start, end int) bool {
+	isWord := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+	}
+	return (start == 0 || !isWord(text[start-1])) && (end >= len(text) || !isWord(text[end]))
+}
+
+// styledWords separa os trechos em palavras, aplicando o estilo de cada parte
+func styledWords(segments []segment) []string {
+	var words []string
+	var current strings.Builder
+
+	for _, seg := range segments {
+		for j, part := range strings.Split(seg.text, " ") {
+			if j > 0 && current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+			current.WriteString(seg.style.apply(part))
+		}
+	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+	return words
+}
@@ -0,0 +1,93 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+)
+
+// runeWidth retorna quantas colunas o caractere ocupa no terminal
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200d || (r >= 0xfe00 && r <= 0xfe0f):
+		return 0
+	case r < 0x1100:
+		return 1
+	case r <= 0x115f, // Hangul Jamo
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f, // CJK
+		r >= 0xac00 && r <= 0xd7a3,                // Hangul
+		r >= 0xf900 && r <= 0xfaff,                // CJK compatibilidade
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60, // Formas de largura total
+		r >= 0xffe0 && r <= 0xffe6,
+		isWideSymbol(r),
+		r >= 0x1f300 && r <= 0x1faff, // Emoji
+		r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
+
+// isWideSymbol cobre os símbolos do bloco 2600–27BF exibidos como emoji
+func isWideSymbol(r rune) bool {
+	switch r {
+	case '☔', '☕', '♈', '♉', '♊', '♋', '♌', '♍', '♎', '♏', '♐', '♑', '♒', '♓', '♿', '⚓', '⚡', '⚪', '⚫',
+		'⚽', '⚾', '⛄', '⛅', '⛎', '⛔', '⛪', '⛲', '⛳', '⛵', '⛺', '⛽', '✅', '✊', '✋', '✨', '❌', '❎', '❓', '❔', '❕', '❗', '➕', '➖', '➗', '➰', '➿':
+		return true
+	}
+	return false
+}
+
+// displayWidth retorna a largura visível do texto, ignorando sequências ANSI
+func displayWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			if r == 'm' {
+				inEscape = false
+			}
+		case r == '\033':
+			inEscape = true
+		default:
+			width += runeWidth(r)
+		}
+	}
+	return width
+}
+
+// padRight completa o texto com espaços até a largura visível informada
+func padRight(s string, width int) string {
+	if n := width - displayWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// wrapWords distribui palavras já estilizadas em linhas de até width colunas.
+// Palavras maiores que a linha ficam sozinhas, sem quebra no meio.
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	var current strings.Builder
+	currentWidth := 0
+
+	for _, word := range words {
+		w := displayWidth(word)
+		if currentWidth > 0 && currentWidth+1+w > width {
+			lines = append(lines, current.String())
+			current.Reset()
+			currentWidth = 0
+		}
+		if currentWidth > 0 {
+			current.WriteByte(' ')
+			currentWidth++
+		}
+		current.WriteString(word)
+		currentWidth += w
+	}
+
+	if currentWidth > 0 || len(lines) == 0 {
+		lines = append(lines, current.String())
+	}
+	return lines
+}